@@ -0,0 +1,280 @@
+package activation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Activation pairs an activation function with its derivative. Name
+// identifies the function and, together with Alpha, is enough to
+// build the same activation again.
+type Activation struct {
+	Name       string
+	Alpha      float64 // Parameter of leaky_relu, elu and swish, zero for the others
+	Function   func(x float64) float64
+	Derivative func(x float64) float64
+
+	// DerivativeFromOutput, when not nil, computes the derivative
+	// at x given y = Function(x), so the backward process can reuse
+	// the output of the forward one instead of its input.
+	DerivativeFromOutput func(y float64) float64
+}
+
+// Validate checks the activation can be used.
+func (a Activation) Validate() error {
+	if a.Function == nil || a.Derivative == nil {
+		return errors.New("activation function and its derivative must be provided")
+	}
+	return nil
+}
+
+// ByName builds the activation identified by name, giving alpha to
+// the ones that take a parameter. It is the inverse of reading the
+// Name and Alpha of an activation.
+func ByName(name string, alpha float64) (Activation, error) {
+	switch name {
+	case "sigmoid":
+		return NewSigmoid(), nil
+	case "identity":
+		return NewIdentity(), nil
+	case "relu":
+		return NewReLU(), nil
+	case "leaky_relu":
+		return NewLeakyReLU(alpha), nil
+	case "elu":
+		return NewELU(alpha), nil
+	case "selu":
+		return NewSELU(), nil
+	case "tanh":
+		return NewTanh(), nil
+	case "softplus":
+		return NewSoftplus(), nil
+	case "swish":
+		return NewSwish(alpha), nil
+	case "gelu":
+		return NewGELU(), nil
+	case "hard_sigmoid":
+		return NewHardSigmoid(), nil
+	}
+	return Activation{}, fmt.Errorf("unknown activation %q", name)
+}
+
+// Constants of SELU, chosen so activations keep zero mean and unit variance.
+const (
+	seluLambda = 1.0507009873554805
+	seluAlpha  = 1.6732632423543772
+)
+
+func NewSigmoid() Activation {
+	return Activation{
+		Name:                 "sigmoid",
+		Function:             Sigmoid,
+		Derivative:           SigmoidPrime,
+		DerivativeFromOutput: SigmoidPrimeFromOutput,
+	}
+}
+
+// NewIdentity returns f(x) = x, useful on output layers
+// predicting unbounded values.
+func NewIdentity() Activation {
+	return Activation{
+		Name:                 "identity",
+		Function:             func(x float64) float64 { return x },
+		Derivative:           func(x float64) float64 { return 1 },
+		DerivativeFromOutput: func(y float64) float64 { return 1 },
+	}
+}
+
+// NewReLU returns f(x) = max(0, x).
+func NewReLU() Activation {
+	return Activation{
+		Name:                 "relu",
+		Function:             func(x float64) float64 { return math.Max(0, x) },
+		Derivative:           step,
+		DerivativeFromOutput: step,
+	}
+}
+
+// NewLeakyReLU returns f(x) = x for x > 0 and alpha*x otherwise.
+func NewLeakyReLU(alpha float64) Activation {
+	a := Activation{
+		Name:  "leaky_relu",
+		Alpha: alpha,
+		Function: func(x float64) float64 {
+			if x > 0 {
+				return x
+			}
+			return alpha * x
+		},
+		Derivative: func(x float64) float64 {
+			if x > 0 {
+				return 1
+			}
+			return alpha
+		},
+	}
+	if alpha > 0 {
+		// y has the sign of x only when alpha is positive
+		a.DerivativeFromOutput = a.Derivative
+	}
+	return a
+}
+
+// NewELU returns f(x) = x for x > 0 and alpha*(e^x - 1) otherwise.
+func NewELU(alpha float64) Activation {
+	a := Activation{
+		Name:  "elu",
+		Alpha: alpha,
+		Function: func(x float64) float64 {
+			if x > 0 {
+				return x
+			}
+			return alpha * math.Expm1(x)
+		},
+		Derivative: func(x float64) float64 {
+			if x > 0 {
+				return 1
+			}
+			return alpha * math.Exp(x)
+		},
+	}
+	if alpha > 0 {
+		// for x <= 0, alpha*e^x = y + alpha
+		a.DerivativeFromOutput = func(y float64) float64 {
+			if y > 0 {
+				return 1
+			}
+			return y + alpha
+		}
+	}
+	return a
+}
+
+// NewSELU returns the scaled ELU, lambda*elu(x) with fixed lambda and alpha.
+func NewSELU() Activation {
+	return Activation{
+		Name: "selu",
+		Function: func(x float64) float64 {
+			if x > 0 {
+				return seluLambda * x
+			}
+			return seluLambda * seluAlpha * math.Expm1(x)
+		},
+		Derivative: func(x float64) float64 {
+			if x > 0 {
+				return seluLambda
+			}
+			return seluLambda * seluAlpha * math.Exp(x)
+		},
+		DerivativeFromOutput: func(y float64) float64 {
+			if y > 0 {
+				return seluLambda
+			}
+			return y + seluLambda*seluAlpha
+		},
+	}
+}
+
+// NewTanh returns the hyperbolic tangent.
+func NewTanh() Activation {
+	return Activation{
+		Name:     "tanh",
+		Function: math.Tanh,
+		Derivative: func(x float64) float64 {
+			tanh := math.Tanh(x)
+			return 1 - tanh*tanh
+		},
+		DerivativeFromOutput: func(y float64) float64 {
+			return 1 - y*y
+		},
+	}
+}
+
+// NewSoftplus returns f(x) = log(1 + e^x), a smooth ReLU.
+func NewSoftplus() Activation {
+	return Activation{
+		Name: "softplus",
+		Function: func(x float64) float64 {
+			// max(x, 0) + log(1 + e^(-|x|)) doesn't overflow for large x
+			return math.Max(x, 0) + math.Log1p(math.Exp(-math.Abs(x)))
+		},
+		Derivative: Sigmoid,
+		// sigmoid(x) = 1 - e^(-y)
+		DerivativeFromOutput: func(y float64) float64 {
+			return -math.Expm1(-y)
+		},
+	}
+}
+
+// NewSwish returns f(x) = x*sigmoid(beta*x). Its derivative cannot
+// be told from the output, so DerivativeFromOutput is nil.
+func NewSwish(beta float64) Activation {
+	return Activation{
+		Name:  "swish",
+		Alpha: beta,
+		Function: func(x float64) float64 {
+			return x * Sigmoid(beta*x)
+		},
+		Derivative: func(x float64) float64 {
+			sigmoid := Sigmoid(beta * x)
+			return sigmoid + beta*x*sigmoid*(1-sigmoid)
+		},
+	}
+}
+
+// NewSiLU returns the sigmoid linear unit, which is swish with beta = 1.
+func NewSiLU() Activation {
+	return NewSwish(1)
+}
+
+// NewGELU returns the gaussian error linear unit, f(x) = x*P(X <= x)
+// where X follows the standard normal distribution. As with swish,
+// DerivativeFromOutput is nil.
+func NewGELU() Activation {
+	return Activation{
+		Name: "gelu",
+		Function: func(x float64) float64 {
+			return x * standardNormalCDF(x)
+		},
+		Derivative: func(x float64) float64 {
+			return standardNormalCDF(x) + x*math.Exp(-0.5*x*x)/math.Sqrt(2*math.Pi)
+		},
+	}
+}
+
+// NewHardSigmoid returns a piecewise linear approximation of
+// sigmoid, f(x) = min(max(x/6 + 0.5, 0), 1).
+func NewHardSigmoid() Activation {
+	return Activation{
+		Name: "hard_sigmoid",
+		Function: func(x float64) float64 {
+			return math.Min(math.Max(x/6+0.5, 0), 1)
+		},
+		Derivative: func(x float64) float64 {
+			if x > -3 && x < 3 {
+				return 1.0 / 6
+			}
+			return 0
+		},
+		DerivativeFromOutput: func(y float64) float64 {
+			if y > 0 && y < 1 {
+				return 1.0 / 6
+			}
+			return 0
+		},
+	}
+}
+
+// step is the derivative of relu, which is the same whether
+// it is given x or y = relu(x).
+func step(value float64) float64 {
+	if value > 0 {
+		return 1
+	}
+	return 0
+}
+
+func standardNormalCDF(x float64) float64 {
+	return 0.5 * (1 + math.Erf(x/math.Sqrt2))
+}
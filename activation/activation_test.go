@@ -0,0 +1,138 @@
+package activation_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/activation"
+)
+
+func allActivations() []activation.Activation {
+	return []activation.Activation{
+		activation.NewSigmoid(),
+		activation.NewIdentity(),
+		activation.NewReLU(),
+		activation.NewLeakyReLU(0.01),
+		activation.NewELU(1),
+		activation.NewSELU(),
+		activation.NewTanh(),
+		activation.NewSoftplus(),
+		activation.NewSwish(1.5),
+		activation.NewSiLU(),
+		activation.NewGELU(),
+		activation.NewHardSigmoid(),
+	}
+}
+
+func TestDerivativesMatchNumericalDerivatives(t *testing.T) {
+	smallDifference := 1e-6
+	// points away from the kinks of relu like functions
+	inputs := []float64{-4.1, -2.5, -0.7, -0.1, 0.2, 0.9, 2.2, 5.3}
+	for _, a := range allActivations() {
+		if err := a.Validate(); err != nil {
+			t.Errorf("%s: expected err to be nil, got %v", a.Name, err)
+		}
+		for _, x := range inputs {
+			numerical := (a.Function(x+smallDifference) - a.Function(x-smallDifference)) / (2 * smallDifference)
+			if derivative := a.Derivative(x); math.Abs(derivative-numerical) > 1e-5 {
+				t.Errorf("%s: expected derivative at %v to be %v, got %v", a.Name, x, numerical, derivative)
+			}
+		}
+	}
+}
+
+func TestDerivativesFromOutputMatchDerivatives(t *testing.T) {
+	inputs := []float64{-4.1, -2.5, -0.7, -0.1, 0.2, 0.9, 2.2, 5.3}
+	withoutDerivativeFromOutput := map[string]bool{"swish": true, "gelu": true}
+	for _, a := range allActivations() {
+		if a.DerivativeFromOutput == nil {
+			if !withoutDerivativeFromOutput[a.Name] {
+				t.Errorf("%s: expected derivative from output to be set", a.Name)
+			}
+			continue
+		}
+		for _, x := range inputs {
+			expected := a.Derivative(x)
+			if result := a.DerivativeFromOutput(a.Function(x)); math.Abs(result-expected) > acceptedError {
+				t.Errorf("%s: expected derivative from output at %v to be %v, got %v", a.Name, x, expected, result)
+			}
+		}
+	}
+}
+
+func TestLeakyReLUWithNegativeAlphaHasNoDerivativeFromOutput(t *testing.T) {
+	if activation.NewLeakyReLU(-0.5).DerivativeFromOutput != nil {
+		t.Errorf("expected derivative from output to be nil")
+	}
+}
+
+func TestActivationValues(t *testing.T) {
+	testCases := []struct {
+		activation activation.Activation
+		input      float64
+		expected   float64
+	}{
+		{activation: activation.NewIdentity(), input: -3.5, expected: -3.5},
+		{activation: activation.NewReLU(), input: -2, expected: 0},
+		{activation: activation.NewReLU(), input: 2, expected: 2},
+		{activation: activation.NewLeakyReLU(0.1), input: -2, expected: -0.2},
+		{activation: activation.NewELU(1), input: -1, expected: math.Exp(-1) - 1},
+		{activation: activation.NewSELU(), input: 1, expected: 1.0507009873554805},
+		{activation: activation.NewTanh(), input: 0.5, expected: math.Tanh(0.5)},
+		{activation: activation.NewSoftplus(), input: 1, expected: math.Log(1 + math.E)},
+		{activation: activation.NewSoftplus(), input: 1000, expected: 1000},
+		{activation: activation.NewSiLU(), input: 1, expected: 1 / (1 + math.Exp(-1))},
+		{activation: activation.NewGELU(), input: 0, expected: 0},
+		{activation: activation.NewGELU(), input: 1, expected: 0.8413447460685429},
+		{activation: activation.NewHardSigmoid(), input: 0, expected: 0.5},
+		{activation: activation.NewHardSigmoid(), input: 4, expected: 1},
+	}
+	for _, testCase := range testCases {
+		result := testCase.activation.Function(testCase.input)
+		if math.Abs(result-testCase.expected) > acceptedError {
+			t.Errorf("%s: expected value at %v to be %v, got %v", testCase.activation.Name, testCase.input, testCase.expected, result)
+		}
+	}
+}
+
+func TestNamesAreUnique(t *testing.T) {
+	names := map[string]bool{}
+	for _, a := range allActivations() {
+		if a.Name == "" {
+			t.Errorf("expected every activation to have a name")
+		}
+		names[a.Name] = true
+	}
+	// silu is swish with beta = 1, so it shares its name
+	if expected := len(allActivations()) - 1; len(names) != expected {
+		t.Errorf("expected %d distinct names, got %d", expected, len(names))
+	}
+}
+
+func TestByNameRebuildsActivations(t *testing.T) {
+	for _, a := range allActivations() {
+		rebuilt, err := activation.ByName(a.Name, a.Alpha)
+		if err != nil {
+			t.Errorf("%s: expected err to be nil, got %v", a.Name, err)
+			continue
+		}
+		for _, x := range []float64{-2.5, -0.3, 0.4, 1.7} {
+			if rebuilt.Function(x) != a.Function(x) || rebuilt.Derivative(x) != a.Derivative(x) {
+				t.Errorf("%s: expected rebuilt activation to match the original one at %v", a.Name, x)
+			}
+		}
+	}
+}
+
+func TestByNameWithUnknownName(t *testing.T) {
+	if _, err := activation.ByName("custom", 0); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+}
+
+func TestValidateWithoutDerivative(t *testing.T) {
+	a := activation.Activation{Name: "broken", Function: activation.Sigmoid}
+	if err := a.Validate(); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+}
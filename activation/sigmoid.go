@@ -2,12 +2,27 @@ package activation
 
 import "math"
 
-// Sigmoid implements the sigmoid function
+// Sigmoid implements the sigmoid function. It branches on the sign
+// of x so math.Exp is only called with non positive values and
+// never overflows.
 func Sigmoid(x float64) float64 {
-	return 1 / (1 + math.Pow(math.E, -x))
+	if x >= 0 {
+		return 1 / (1 + math.Exp(-x))
+	}
+	e := math.Exp(x)
+	return e / (1 + e)
 }
 
-// SigmoidPrime implements the derivative of a sigmoid function
+// SigmoidPrime implements the derivative of a sigmoid function. As the
+// derivative is symmetric it is computed as e^(-|x|) / (1 + e^(-|x|))^2,
+// which doesn't become Inf/Inf for large negative inputs.
 func SigmoidPrime(x float64) float64 {
-	return math.Pow(math.E, -x) / math.Pow((1+math.Pow(math.E, -x)), 2)
+	e := math.Exp(-math.Abs(x))
+	return e / ((1 + e) * (1 + e))
+}
+
+// SigmoidPrimeFromOutput implements the derivative of a sigmoid
+// function given its output y = Sigmoid(x), which is y*(1-y).
+func SigmoidPrimeFromOutput(y float64) float64 {
+	return y * (1 - y)
 }
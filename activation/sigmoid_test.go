@@ -45,3 +45,36 @@ func TestSigmoidPrime(t *testing.T) {
 		}
 	}
 }
+
+func TestSigmoidWithLargeInputs(t *testing.T) {
+	testCases := []struct {
+		input         float64
+		expected      float64
+		expectedPrime float64
+	}{
+		{input: -1000, expected: 0, expectedPrime: 0},
+		{input: -745, expected: 0, expectedPrime: 0},
+		{input: 1000, expected: 1, expectedPrime: 0},
+		{input: -30, expected: 9.357622968840175e-14, expectedPrime: 9.357622968839299e-14},
+	}
+	for _, testCase := range testCases {
+		result := activation.Sigmoid(testCase.input)
+		if math.IsNaN(result) || math.Abs(testCase.expected-result) > acceptedError {
+			t.Errorf("expected sigmoid of %v to be %v, got %v", testCase.input, testCase.expected, result)
+		}
+		prime := activation.SigmoidPrime(testCase.input)
+		if math.IsNaN(prime) || math.Abs(testCase.expectedPrime-prime) > acceptedError {
+			t.Errorf("expected sigmoid prime of %v to be %v, got %v", testCase.input, testCase.expectedPrime, prime)
+		}
+	}
+}
+
+func TestSigmoidPrimeFromOutput(t *testing.T) {
+	for _, x := range []float64{-13, -1, 0, 0.5, 4} {
+		expected := activation.SigmoidPrime(x)
+		result := activation.SigmoidPrimeFromOutput(activation.Sigmoid(x))
+		if math.Abs(expected-result) > acceptedError {
+			t.Errorf("expected sigmoid prime of %v from output to be %v, got %v", x, expected, result)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package activation
+
+import "math"
+
+// Softmax turns the given scores into probabilities that sum to 1. Unlike
+// the other activations it depends on every value of a row, so it cannot
+// be applied element wise. The max value is subtracted before
+// exponentiating to avoid overflow.
+func Softmax(values []float64) []float64 {
+	probabilities := make([]float64, len(values))
+	SoftmaxInto(probabilities, values)
+	return probabilities
+}
+
+// SoftmaxInto writes the probabilities of the given scores on dst,
+// which must have as many elements and can be values itself.
+func SoftmaxInto(dst, values []float64) {
+	maxValue := math.Inf(-1)
+	for _, value := range values {
+		maxValue = math.Max(maxValue, value)
+	}
+	sum := 0.0
+	for i, value := range values {
+		dst[i] = math.Exp(value - maxValue)
+		sum += dst[i]
+	}
+	for i := range dst[:len(values)] {
+		dst[i] /= sum
+	}
+}
@@ -0,0 +1,43 @@
+package activation_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/activation"
+)
+
+func TestSoftmax(t *testing.T) {
+	sumOfExps := math.Exp(1) + math.Exp(2) + math.Exp(3)
+	expected := []float64{math.Exp(1) / sumOfExps, math.Exp(2) / sumOfExps, math.Exp(3) / sumOfExps}
+
+	result := activation.Softmax([]float64{1, 2, 3})
+	for i := range expected {
+		if math.Abs(result[i]-expected[i]) > acceptedError {
+			t.Errorf("expected probability %d to be %v, got %v", i, expected[i], result[i])
+		}
+	}
+}
+
+func TestSoftmaxWithLargeValues(t *testing.T) {
+	result := activation.Softmax([]float64{1000, 1000, -1000})
+	expected := []float64{0.5, 0.5, 0}
+	for i := range expected {
+		if math.IsNaN(result[i]) || math.Abs(result[i]-expected[i]) > acceptedError {
+			t.Errorf("expected probability %d to be %v, got %v", i, expected[i], result[i])
+		}
+	}
+}
+
+func TestSoftmaxIntoValues(t *testing.T) {
+	values := []float64{1, 2, 3}
+	expected := activation.Softmax(values)
+
+	activation.SoftmaxInto(values, values)
+
+	for i := range expected {
+		if values[i] != expected[i] {
+			t.Errorf("expected probability %d to be %v, got %v", i, expected[i], values[i])
+		}
+	}
+}
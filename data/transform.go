@@ -1,6 +1,8 @@
 package data
 
 import (
+	"fmt"
+
 	"github.com/buarki/supervised-machine-learning/matrix"
 	"github.com/buarki/supervised-machine-learning/neuralnet"
 )
@@ -28,3 +30,24 @@ func Transform(samples []Sample) ([]neuralnet.TrainingData, error) {
 	}
 	return trainingData, nil
 }
+
+// TransformAll transforms the samples into a single training data
+// having one sample on each row, so Train can batch them with
+// neuralnet.WithBatchSize
+func TransformAll(samples []Sample) (neuralnet.TrainingData, error) {
+	xData := make([]float64, 0, 2*len(samples))
+	yData := make([]float64, 0, len(samples))
+	for _, s := range samples {
+		xData = append(xData, float64(s.HoursOfSleep), float64(s.HoursOfMeditation))
+		yData = append(yData, float64(s.ScoreTest))
+	}
+	X, err := matrix.New(len(samples), 2, xData)
+	if err != nil {
+		return neuralnet.TrainingData{}, fmt.Errorf("failed to create X, got %v", err)
+	}
+	Y, err := matrix.New(len(samples), 1, yData)
+	if err != nil {
+		return neuralnet.TrainingData{}, fmt.Errorf("failed to create Y, got %v", err)
+	}
+	return neuralnet.TrainingData{X: X, Y: Y}, nil
+}
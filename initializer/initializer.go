@@ -0,0 +1,258 @@
+// Package initializer provides strategies to choose the initial values
+// of params, which deeper networks need to keep activations and
+// gradients from vanishing or exploding.
+package initializer
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+// Initializer gives the initial values of a rows x columns param. For
+// weights, rows is the fan in (inputs of the layer) and columns the fan
+// out (neurons of the layer).
+type Initializer interface {
+	Initialize(rows, columns int, random *rand.Rand) (*matrix.Matrix, error)
+}
+
+// Shaped is implemented by initializers only able to give params of one
+// shape, so it can be checked before building the params.
+type Shaped interface {
+	Shape() (rows, columns int)
+}
+
+// Uniform samples values uniformly from [-limit, limit).
+type Uniform struct {
+	limit float64
+}
+
+func NewUniform(limit float64) (*Uniform, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be > 0, received %v", limit)
+	}
+	return &Uniform{limit: limit}, nil
+}
+
+func (i *Uniform) Initialize(rows, columns int, random *rand.Rand) (*matrix.Matrix, error) {
+	return uniform(rows, columns, i.limit, random)
+}
+
+// GlorotUniform, also known as Xavier uniform, samples uniformly from
+// [-limit, limit) with limit = sqrt(6/(fanIn + fanOut)). It suits
+// sigmoid, tanh and linear layers.
+type GlorotUniform struct{}
+
+func NewGlorotUniform() *GlorotUniform {
+	return &GlorotUniform{}
+}
+
+func (i *GlorotUniform) Initialize(rows, columns int, random *rand.Rand) (*matrix.Matrix, error) {
+	return uniform(rows, columns, math.Sqrt(6/float64(rows+columns)), random)
+}
+
+// GlorotNormal samples from a normal distribution with mean 0
+// and standard deviation sqrt(2/(fanIn + fanOut)).
+type GlorotNormal struct{}
+
+func NewGlorotNormal() *GlorotNormal {
+	return &GlorotNormal{}
+}
+
+func (i *GlorotNormal) Initialize(rows, columns int, random *rand.Rand) (*matrix.Matrix, error) {
+	return normal(rows, columns, math.Sqrt(2/float64(rows+columns)), random)
+}
+
+// HeUniform samples uniformly from [-limit, limit) with limit = sqrt(6/fanIn).
+// It suits ReLU like layers, which zero half of their inputs.
+type HeUniform struct{}
+
+func NewHeUniform() *HeUniform {
+	return &HeUniform{}
+}
+
+func (i *HeUniform) Initialize(rows, columns int, random *rand.Rand) (*matrix.Matrix, error) {
+	return uniform(rows, columns, math.Sqrt(6/float64(rows)), random)
+}
+
+// HeNormal samples from a normal distribution with mean 0
+// and standard deviation sqrt(2/fanIn).
+type HeNormal struct{}
+
+func NewHeNormal() *HeNormal {
+	return &HeNormal{}
+}
+
+func (i *HeNormal) Initialize(rows, columns int, random *rand.Rand) (*matrix.Matrix, error) {
+	return normal(rows, columns, math.Sqrt(2/float64(rows)), random)
+}
+
+// LeCunUniform samples uniformly from [-limit, limit) with limit = sqrt(3/fanIn).
+type LeCunUniform struct{}
+
+func NewLeCunUniform() *LeCunUniform {
+	return &LeCunUniform{}
+}
+
+func (i *LeCunUniform) Initialize(rows, columns int, random *rand.Rand) (*matrix.Matrix, error) {
+	return uniform(rows, columns, math.Sqrt(3/float64(rows)), random)
+}
+
+// LeCunNormal samples from a normal distribution with mean 0 and standard
+// deviation sqrt(1/fanIn). It is the one SELU layers need to self normalize.
+type LeCunNormal struct{}
+
+func NewLeCunNormal() *LeCunNormal {
+	return &LeCunNormal{}
+}
+
+func (i *LeCunNormal) Initialize(rows, columns int, random *rand.Rand) (*matrix.Matrix, error) {
+	return normal(rows, columns, math.Sqrt(1/float64(rows)), random)
+}
+
+// Orthogonal gives a matrix whose columns (or rows, when there are fewer
+// rows than columns) are orthonormal, multiplied by gain. It is built by
+// orthonormalizing a matrix of normal samples with Gram-Schmidt.
+type Orthogonal struct {
+	gain float64
+}
+
+func NewOrthogonal(gain float64) (*Orthogonal, error) {
+	if gain <= 0 {
+		return nil, fmt.Errorf("gain must be > 0, received %v", gain)
+	}
+	return &Orthogonal{gain: gain}, nil
+}
+
+func (i *Orthogonal) Initialize(rows, columns int, random *rand.Rand) (*matrix.Matrix, error) {
+	if err := checkShape(rows, columns, random); err != nil {
+		return nil, err
+	}
+	// vectors to orthonormalize are the columns of a tall matrix and the rows
+	// of a wide one, so there are never more of them than their dimension
+	amount, size := columns, rows
+	if rows < columns {
+		amount, size = rows, columns
+	}
+	vectors := make([][]float64, amount)
+	for k := range vectors {
+		for {
+			vectors[k] = make([]float64, size)
+			for j := range vectors[k] {
+				vectors[k][j] = random.NormFloat64()
+			}
+			for _, previous := range vectors[:k] {
+				projection := dot(vectors[k], previous)
+				for j := range vectors[k] {
+					vectors[k][j] -= projection * previous[j]
+				}
+			}
+			// a sample lying on the span of the previous vectors is drawn again
+			if norm := math.Sqrt(dot(vectors[k], vectors[k])); norm > 1e-10 {
+				for j := range vectors[k] {
+					vectors[k][j] /= norm
+				}
+				break
+			}
+		}
+	}
+	values := make([]float64, rows*columns)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < columns; c++ {
+			if rows >= columns {
+				values[r*columns+c] = i.gain * vectors[c][r]
+			} else {
+				values[r*columns+c] = i.gain * vectors[r][c]
+			}
+		}
+	}
+	return matrix.New(rows, columns, values)
+}
+
+// Constant gives the same value to every element.
+type Constant struct {
+	value float64
+}
+
+func NewConstant(value float64) *Constant {
+	return &Constant{value: value}
+}
+
+// NewZeros gives zero to every element, the usual choice for biases.
+func NewZeros() *Constant {
+	return NewConstant(0)
+}
+
+func (i *Constant) Initialize(rows, columns int, random *rand.Rand) (*matrix.Matrix, error) {
+	values := make([]float64, rows*columns)
+	for j := range values {
+		values[j] = i.value
+	}
+	return matrix.New(rows, columns, values)
+}
+
+// FromMatrix gives a copy of a matrix supplied by the caller,
+// which must have the shape of the param.
+type FromMatrix struct {
+	m *matrix.Matrix
+}
+
+func NewFromMatrix(m *matrix.Matrix) (*FromMatrix, error) {
+	if m == nil {
+		return nil, fmt.Errorf("matrix cannot be nil")
+	}
+	return &FromMatrix{m: m}, nil
+}
+
+func (i *FromMatrix) Shape() (int, int) {
+	return i.m.Rows, i.m.Columns
+}
+
+func (i *FromMatrix) Initialize(rows, columns int, random *rand.Rand) (*matrix.Matrix, error) {
+	if i.m.Rows != rows || i.m.Columns != columns {
+		return nil, fmt.Errorf("given matrix has shape (%dx%d), expected (%dx%d)", i.m.Rows, i.m.Columns, rows, columns)
+	}
+	return matrix.New(rows, columns, i.m.FlattenedElements())
+}
+
+func uniform(rows, columns int, limit float64, random *rand.Rand) (*matrix.Matrix, error) {
+	if err := checkShape(rows, columns, random); err != nil {
+		return nil, err
+	}
+	values := make([]float64, rows*columns)
+	for j := range values {
+		values[j] = (random.Float64()*2 - 1) * limit
+	}
+	return matrix.New(rows, columns, values)
+}
+
+func normal(rows, columns int, standardDeviation float64, random *rand.Rand) (*matrix.Matrix, error) {
+	if err := checkShape(rows, columns, random); err != nil {
+		return nil, err
+	}
+	values := make([]float64, rows*columns)
+	for j := range values {
+		values[j] = random.NormFloat64() * standardDeviation
+	}
+	return matrix.New(rows, columns, values)
+}
+
+func checkShape(rows, columns int, random *rand.Rand) error {
+	if rows <= 0 || columns <= 0 {
+		return fmt.Errorf("rows and columns must be > 0, received (%dx%d)", rows, columns)
+	}
+	if random == nil {
+		return fmt.Errorf("random cannot be nil")
+	}
+	return nil
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
@@ -0,0 +1,176 @@
+package initializer_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/initializer"
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+func statisticsOf(m *matrix.Matrix) (float64, float64, float64) {
+	values := m.FlattenedElements()
+	mean, maxAbs := 0.0, 0.0
+	for _, value := range values {
+		mean += value
+		maxAbs = math.Max(maxAbs, math.Abs(value))
+	}
+	mean /= float64(len(values))
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values))), maxAbs
+}
+
+func TestRandomInitializersFollowTheirDistributions(t *testing.T) {
+	fanIn, fanOut := 200, 300
+	uniform, err := initializer.NewUniform(0.5)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	testCases := []struct {
+		name                      string
+		initializer               initializer.Initializer
+		expectedStandardDeviation float64
+		limit                     float64 // zero for unbounded distributions
+	}{
+		{name: "uniform", initializer: uniform, expectedStandardDeviation: 0.5 / math.Sqrt(3), limit: 0.5},
+		{name: "glorot uniform", initializer: initializer.NewGlorotUniform(), expectedStandardDeviation: math.Sqrt(2.0 / 500), limit: math.Sqrt(6.0 / 500)},
+		{name: "glorot normal", initializer: initializer.NewGlorotNormal(), expectedStandardDeviation: math.Sqrt(2.0 / 500)},
+		{name: "he uniform", initializer: initializer.NewHeUniform(), expectedStandardDeviation: math.Sqrt(2.0 / 200), limit: math.Sqrt(6.0 / 200)},
+		{name: "he normal", initializer: initializer.NewHeNormal(), expectedStandardDeviation: math.Sqrt(2.0 / 200)},
+		{name: "lecun uniform", initializer: initializer.NewLeCunUniform(), expectedStandardDeviation: math.Sqrt(1.0 / 200), limit: math.Sqrt(3.0 / 200)},
+		{name: "lecun normal", initializer: initializer.NewLeCunNormal(), expectedStandardDeviation: math.Sqrt(1.0 / 200)},
+	}
+	for _, testCase := range testCases {
+		m, err := testCase.initializer.Initialize(fanIn, fanOut, rand.New(rand.NewSource(7)))
+		if err != nil {
+			t.Errorf("%s: expected err to be nil, got %v", testCase.name, err)
+			continue
+		}
+		mean, standardDeviation, maxAbs := statisticsOf(m)
+		if math.Abs(mean) > 0.05*testCase.expectedStandardDeviation {
+			t.Errorf("%s: expected mean to be close to 0, got %v", testCase.name, mean)
+		}
+		if math.Abs(standardDeviation-testCase.expectedStandardDeviation) > 0.02*testCase.expectedStandardDeviation {
+			t.Errorf("%s: expected standard deviation to be %v, got %v", testCase.name, testCase.expectedStandardDeviation, standardDeviation)
+		}
+		if testCase.limit > 0 && maxAbs > testCase.limit {
+			t.Errorf("%s: expected values to be within %v, got %v", testCase.name, testCase.limit, maxAbs)
+		}
+	}
+}
+
+func TestOrthogonal(t *testing.T) {
+	o, err := initializer.NewOrthogonal(2)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	for _, shape := range [][2]int{{5, 3}, {3, 5}, {4, 4}} {
+		m, err := o.Initialize(shape[0], shape[1], rand.New(rand.NewSource(7)))
+		if err != nil {
+			t.Errorf("expected err to be nil, got %v", err)
+			continue
+		}
+		// for tall matrices W^T*W = gain^2*I, for wide ones W*W^T = gain^2*I
+		product, err := m.T().DotProductWith(m)
+		if shape[0] < shape[1] {
+			product, err = m.DotProductWith(m.T())
+		}
+		if err != nil {
+			t.Errorf("expected err to be nil, got %v", err)
+			continue
+		}
+		for i := 0; i < product.Rows; i++ {
+			for j := 0; j < product.Columns; j++ {
+				expected := 0.0
+				if i == j {
+					expected = 4
+				}
+				if value, _ := product.GetAt(i, j); math.Abs(value-expected) > 1e-9 {
+					t.Errorf("expected element (%d, %d) of the product of a (%dx%d) matrix to be %v, got %v", i, j, shape[0], shape[1], expected, value)
+				}
+			}
+		}
+	}
+}
+
+func TestConstantAndZeros(t *testing.T) {
+	for _, testCase := range []struct {
+		initializer initializer.Initializer
+		expected    float64
+	}{
+		{initializer: initializer.NewConstant(0.1), expected: 0.1},
+		{initializer: initializer.NewZeros(), expected: 0},
+	} {
+		m, err := testCase.initializer.Initialize(1, 4, nil)
+		if err != nil {
+			t.Errorf("expected err to be nil, got %v", err)
+			continue
+		}
+		for _, value := range m.FlattenedElements() {
+			if value != testCase.expected {
+				t.Errorf("expected every value to be %v, got %v", testCase.expected, value)
+			}
+		}
+	}
+}
+
+func TestFromMatrix(t *testing.T) {
+	m, err := matrix.New(2, 2, []float64{1, 2, 3, 4})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	i, err := initializer.NewFromMatrix(m)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	initialized, err := i.Initialize(2, 2, nil)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if diff, _ := initialized.FrobeniusNormRatio(m); diff != 0 {
+		t.Errorf("expected initialized matrix to be the given one, got diff %v", diff)
+	}
+	if initialized == m {
+		t.Errorf("expected initialized matrix to be a copy")
+	}
+	if _, err := i.Initialize(2, 3, nil); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+	if rows, columns := i.Shape(); rows != 2 || columns != 2 {
+		t.Errorf("expected shape to be (2x2), got (%dx%d)", rows, columns)
+	}
+}
+
+func TestSameSeedGivesSameValues(t *testing.T) {
+	first, err := initializer.NewHeNormal().Initialize(3, 4, rand.New(rand.NewSource(11)))
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	second, err := initializer.NewHeNormal().Initialize(3, 4, rand.New(rand.NewSource(11)))
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if diff, _ := first.FrobeniusNormRatio(second); diff != 0 {
+		t.Errorf("expected same values, got diff %v", diff)
+	}
+}
+
+func TestInvalidInitializers(t *testing.T) {
+	if _, err := initializer.NewUniform(0); err == nil {
+		t.Errorf("expected err to be not nil for uniform")
+	}
+	if _, err := initializer.NewOrthogonal(-1); err == nil {
+		t.Errorf("expected err to be not nil for orthogonal")
+	}
+	if _, err := initializer.NewFromMatrix(nil); err == nil {
+		t.Errorf("expected err to be not nil for from matrix")
+	}
+	if _, err := initializer.NewHeNormal().Initialize(0, 3, rand.New(rand.NewSource(1))); err == nil {
+		t.Errorf("expected err to be not nil for empty shape")
+	}
+}
@@ -0,0 +1,407 @@
+package loss
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+// Loss measures how far predictions are from the expected values. Each row
+// of expected and predicted refers to one sample.
+type Loss interface {
+	// Name identifies the loss.
+	Name() string
+	// Value returns the loss of each sample averaged over the samples.
+	Value(expected, predicted *matrix.Matrix) (float64, error)
+	// Gradient returns the derivative of the loss of each sample with respect
+	// to its predictions. It is not averaged, leaving that to the caller.
+	Gradient(expected, predicted *matrix.Matrix) (*matrix.Matrix, error)
+}
+
+// GradientWriter is implemented by losses able to write their gradient on
+// a given matrix shaped as predicted, so it can be reused between batches.
+type GradientWriter interface {
+	GradientInto(dst, expected, predicted *matrix.Matrix) error
+}
+
+// ByName builds the loss identified by name. Parameter is only used
+// by losses that take one, such as the delta of huber.
+func ByName(name string, parameter float64) (Loss, error) {
+	switch name {
+	case "mse":
+		return NewMSE(), nil
+	case "mae":
+		return NewMAE(), nil
+	case "huber":
+		return NewHuber(parameter)
+	case "log_cosh":
+		return NewLogCosh(), nil
+	case "binary_cross_entropy":
+		return NewBinaryCrossEntropy(), nil
+	case "softmax_cross_entropy":
+		return NewSoftmaxCrossEntropy(), nil
+	case "categorical_cross_entropy":
+		return NewCategoricalCrossEntropy(), nil
+	}
+	return nil, fmt.Errorf("unknown loss %q", name)
+}
+
+// epsilon keeps probabilities away from 0 and 1 so logarithms stay finite.
+const epsilon = 1e-12
+
+// MSE is half the squared error of each sample, the 1/2 making its
+// derivative simply predicted - expected.
+type MSE struct{}
+
+func NewMSE() *MSE {
+	return &MSE{}
+}
+
+func (l *MSE) Name() string {
+	return "mse"
+}
+
+func (l *MSE) Value(expected, predicted *matrix.Matrix) (float64, error) {
+	return averageOf(expected, predicted, func(e, p float64) float64 {
+		return 0.5 * (p - e) * (p - e)
+	})
+}
+
+func (l *MSE) Gradient(expected, predicted *matrix.Matrix) (*matrix.Matrix, error) {
+	return gradientOf(l, expected, predicted)
+}
+
+func (l *MSE) GradientInto(dst, expected, predicted *matrix.Matrix) error {
+	return gradientInto(dst, expected, predicted, func(e, p float64) float64 {
+		return p - e
+	})
+}
+
+// MAE is the absolute error of each sample. It is less sensitive to outliers
+// than MSE. Its derivative at zero error is taken as zero.
+type MAE struct{}
+
+func NewMAE() *MAE {
+	return &MAE{}
+}
+
+func (l *MAE) Name() string {
+	return "mae"
+}
+
+func (l *MAE) Value(expected, predicted *matrix.Matrix) (float64, error) {
+	return averageOf(expected, predicted, func(e, p float64) float64 {
+		return math.Abs(p - e)
+	})
+}
+
+func (l *MAE) Gradient(expected, predicted *matrix.Matrix) (*matrix.Matrix, error) {
+	return gradientOf(l, expected, predicted)
+}
+
+func (l *MAE) GradientInto(dst, expected, predicted *matrix.Matrix) error {
+	return gradientInto(dst, expected, predicted, func(e, p float64) float64 {
+		return sign(p - e)
+	})
+}
+
+// Huber is quadratic for errors smaller than delta and linear
+// otherwise, joining the smoothness of MSE and the robustness of MAE.
+type Huber struct {
+	delta float64
+}
+
+func NewHuber(delta float64) (*Huber, error) {
+	if delta <= 0 {
+		return nil, fmt.Errorf("delta must be > 0, received %v", delta)
+	}
+	return &Huber{delta: delta}, nil
+}
+
+func (l *Huber) Name() string {
+	return "huber"
+}
+
+func (l *Huber) Delta() float64 {
+	return l.delta
+}
+
+func (l *Huber) Value(expected, predicted *matrix.Matrix) (float64, error) {
+	return averageOf(expected, predicted, func(e, p float64) float64 {
+		err := math.Abs(p - e)
+		if err <= l.delta {
+			return 0.5 * err * err
+		}
+		return l.delta * (err - 0.5*l.delta)
+	})
+}
+
+func (l *Huber) Gradient(expected, predicted *matrix.Matrix) (*matrix.Matrix, error) {
+	return gradientOf(l, expected, predicted)
+}
+
+func (l *Huber) GradientInto(dst, expected, predicted *matrix.Matrix) error {
+	return gradientInto(dst, expected, predicted, func(e, p float64) float64 {
+		err := p - e
+		if math.Abs(err) <= l.delta {
+			return err
+		}
+		return l.delta * sign(err)
+	})
+}
+
+// LogCosh is log(cosh(error)), which behaves like half the squared
+// error for small errors and like the absolute error for large ones.
+type LogCosh struct{}
+
+func NewLogCosh() *LogCosh {
+	return &LogCosh{}
+}
+
+func (l *LogCosh) Name() string {
+	return "log_cosh"
+}
+
+func (l *LogCosh) Value(expected, predicted *matrix.Matrix) (float64, error) {
+	return averageOf(expected, predicted, func(e, p float64) float64 {
+		// log(cosh(x)) = |x| + log(1 + e^(-2|x|)) - log(2), which doesn't overflow
+		x := math.Abs(p - e)
+		return x + math.Log1p(math.Exp(-2*x)) - math.Ln2
+	})
+}
+
+func (l *LogCosh) Gradient(expected, predicted *matrix.Matrix) (*matrix.Matrix, error) {
+	return gradientOf(l, expected, predicted)
+}
+
+func (l *LogCosh) GradientInto(dst, expected, predicted *matrix.Matrix) error {
+	return gradientInto(dst, expected, predicted, func(e, p float64) float64 {
+		return math.Tanh(p - e)
+	})
+}
+
+// BinaryCrossEntropy is the loss for predictions that are probabilities of
+// independent binary labels, such as the output of a sigmoid. Expected values
+// must be in [0, 1].
+type BinaryCrossEntropy struct{}
+
+func NewBinaryCrossEntropy() *BinaryCrossEntropy {
+	return &BinaryCrossEntropy{}
+}
+
+func (l *BinaryCrossEntropy) Name() string {
+	return "binary_cross_entropy"
+}
+
+func (l *BinaryCrossEntropy) Value(expected, predicted *matrix.Matrix) (float64, error) {
+	return averageOf(expected, predicted, func(e, p float64) float64 {
+		p = clip(p)
+		return -(e*math.Log(p) + (1-e)*math.Log(1-p))
+	})
+}
+
+func (l *BinaryCrossEntropy) Gradient(expected, predicted *matrix.Matrix) (*matrix.Matrix, error) {
+	return gradientOf(l, expected, predicted)
+}
+
+func (l *BinaryCrossEntropy) GradientInto(dst, expected, predicted *matrix.Matrix) error {
+	return gradientInto(dst, expected, predicted, func(e, p float64) float64 {
+		p = clip(p)
+		return (p - e) / (p * (1 - p))
+	})
+}
+
+// SoftmaxCrossEntropy is the loss for multi-class classification. Predictions
+// are the raw scores (logits) of each class, which are turned into probabilities
+// with softmax inside the loss, and each expected row holds the probability of
+// each class, usually one-hot encoded. Its gradient is softmax(predicted) - expected.
+type SoftmaxCrossEntropy struct{}
+
+func NewSoftmaxCrossEntropy() *SoftmaxCrossEntropy {
+	return &SoftmaxCrossEntropy{}
+}
+
+func (l *SoftmaxCrossEntropy) Name() string {
+	return "softmax_cross_entropy"
+}
+
+func (l *SoftmaxCrossEntropy) Value(expected, predicted *matrix.Matrix) (float64, error) {
+	if err := checkShapes(expected, predicted); err != nil {
+		return 0, err
+	}
+	expectedValues := expected.FlattenedElements()
+	logits := predicted.FlattenedElements()
+	sum := 0.0
+	for i := 0; i < predicted.Rows; i++ {
+		row := logits[i*predicted.Columns : (i+1)*predicted.Columns]
+		expectedRow := expectedValues[i*predicted.Columns : (i+1)*predicted.Columns]
+		logSumExp := logSumExpOf(row)
+		for j, logit := range row {
+			// -expected*log(softmax(logit)) = expected*(logSumExp - logit)
+			sum += expectedRow[j] * (logSumExp - logit)
+		}
+	}
+	return sum / float64(predicted.Rows), nil
+}
+
+func (l *SoftmaxCrossEntropy) Gradient(expected, predicted *matrix.Matrix) (*matrix.Matrix, error) {
+	return gradientOf(l, expected, predicted)
+}
+
+func (l *SoftmaxCrossEntropy) GradientInto(dst, expected, predicted *matrix.Matrix) error {
+	if err := checkShapes(expected, predicted); err != nil {
+		return err
+	}
+	if err := checkDestination(dst, predicted); err != nil {
+		return err
+	}
+	expectedValues := expected.FlattenedElements()
+	logits := predicted.FlattenedElements()
+	for i := 0; i < predicted.Rows; i++ {
+		row := logits[i*predicted.Columns : (i+1)*predicted.Columns]
+		expectedRow := expectedValues[i*predicted.Columns : (i+1)*predicted.Columns]
+		logSumExp := logSumExpOf(row)
+		expectedSum := 0.0
+		for _, e := range expectedRow {
+			expectedSum += e
+		}
+		for j, logit := range row {
+			if err := dst.SetAt(i, j, math.Exp(logit-logSumExp)*expectedSum-expectedRow[j]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CategoricalCrossEntropy is the loss for multi-class classification when
+// predictions are already probabilities, such as the output of a softmax
+// layer. Each expected row holds the probability of each class, usually
+// one-hot encoded. Combined with softmax its gradient with respect to the
+// softmax input simplifies to predicted - expected.
+type CategoricalCrossEntropy struct{}
+
+func NewCategoricalCrossEntropy() *CategoricalCrossEntropy {
+	return &CategoricalCrossEntropy{}
+}
+
+func (l *CategoricalCrossEntropy) Name() string {
+	return "categorical_cross_entropy"
+}
+
+func (l *CategoricalCrossEntropy) Value(expected, predicted *matrix.Matrix) (float64, error) {
+	if err := checkShapes(expected, predicted); err != nil {
+		return 0, err
+	}
+	expectedValues := expected.FlattenedElements()
+	sum := 0.0
+	for i, p := range predicted.FlattenedElements() {
+		sum -= expectedValues[i] * math.Log(clip(p))
+	}
+	return sum / float64(predicted.Rows), nil
+}
+
+func (l *CategoricalCrossEntropy) Gradient(expected, predicted *matrix.Matrix) (*matrix.Matrix, error) {
+	return gradientOf(l, expected, predicted)
+}
+
+func (l *CategoricalCrossEntropy) GradientInto(dst, expected, predicted *matrix.Matrix) error {
+	return gradientInto(dst, expected, predicted, func(e, p float64) float64 {
+		return -e / clip(p)
+	})
+}
+
+// logSumExpOf computes log(sum(e^x)) shifting by the max value to avoid overflow.
+func logSumExpOf(values []float64) float64 {
+	maxValue := math.Inf(-1)
+	for _, value := range values {
+		maxValue = math.Max(maxValue, value)
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += math.Exp(value - maxValue)
+	}
+	return maxValue + math.Log(sum)
+}
+
+func averageOf(expected, predicted *matrix.Matrix, elementLoss func(e, p float64) float64) (float64, error) {
+	if err := checkShapes(expected, predicted); err != nil {
+		return 0, err
+	}
+	expectedValues := expected.FlattenedElements()
+	sum := 0.0
+	for i, p := range predicted.FlattenedElements() {
+		sum += elementLoss(expectedValues[i], p)
+	}
+	return sum / float64(predicted.Rows), nil
+}
+
+// gradientOf returns the gradient written by l on a new matrix.
+func gradientOf(l GradientWriter, expected, predicted *matrix.Matrix) (*matrix.Matrix, error) {
+	if err := checkShapes(expected, predicted); err != nil {
+		return nil, err
+	}
+	gradient, err := matrix.NewFromSlice(predicted.Rows, predicted.Columns, make([]float64, predicted.Rows*predicted.Columns))
+	if err != nil {
+		return nil, err
+	}
+	if err := l.GradientInto(gradient, expected, predicted); err != nil {
+		return nil, err
+	}
+	return gradient, nil
+}
+
+func gradientInto(dst, expected, predicted *matrix.Matrix, elementGradient func(e, p float64) float64) error {
+	if err := checkShapes(expected, predicted); err != nil {
+		return err
+	}
+	if err := checkDestination(dst, predicted); err != nil {
+		return err
+	}
+	expectedValues := expected.FlattenedElements()
+	predictedValues := predicted.FlattenedElements()
+	for i := 0; i < predicted.Rows; i++ {
+		for j := 0; j < predicted.Columns; j++ {
+			index := i*predicted.Columns + j
+			if err := dst.SetAt(i, j, elementGradient(expectedValues[index], predictedValues[index])); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkShapes(expected, predicted *matrix.Matrix) error {
+	if expected == nil || predicted == nil {
+		return fmt.Errorf("expected and predicted cannot be nil")
+	}
+	if expected.Rows != predicted.Rows || expected.Columns != predicted.Columns {
+		return fmt.Errorf("expected and predicted have different shapes: expected is (%dx%d), predicted is (%dx%d)", expected.Rows, expected.Columns, predicted.Rows, predicted.Columns)
+	}
+	return nil
+}
+
+func checkDestination(dst, predicted *matrix.Matrix) error {
+	if dst == nil {
+		return fmt.Errorf("destination matrix cannot be nil")
+	}
+	if dst.Rows != predicted.Rows || dst.Columns != predicted.Columns {
+		return fmt.Errorf("destination matrix has shape (%dx%d), predicted is (%dx%d)", dst.Rows, dst.Columns, predicted.Rows, predicted.Columns)
+	}
+	return nil
+}
+
+func clip(p float64) float64 {
+	return math.Min(math.Max(p, epsilon), 1-epsilon)
+}
+
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
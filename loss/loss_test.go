@@ -0,0 +1,226 @@
+package loss_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/loss"
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+const (
+	acceptedError = 1e-6
+)
+
+func newMatrix(t *testing.T, rows, columns int, data []float64) *matrix.Matrix {
+	m, err := matrix.New(rows, columns, data)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	return m
+}
+
+func TestLossValues(t *testing.T) {
+	huber, err := loss.NewHuber(1)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	expected := newMatrix(t, 2, 1, []float64{1, 0})
+	predicted := newMatrix(t, 2, 1, []float64{0.5, 3})
+	testCases := []struct {
+		loss     loss.Loss
+		expected float64
+	}{
+		{loss: loss.NewMSE(), expected: (0.5*0.25 + 0.5*9) / 2},
+		{loss: loss.NewMAE(), expected: (0.5 + 3) / 2},
+		{loss: huber, expected: (0.5*0.25 + (3 - 0.5)) / 2},
+		{loss: loss.NewLogCosh(), expected: (math.Log(math.Cosh(0.5)) + math.Log(math.Cosh(3))) / 2},
+	}
+	for _, testCase := range testCases {
+		value, err := testCase.loss.Value(expected, predicted)
+		if err != nil {
+			t.Errorf("%s: expected err to be nil, got %v", testCase.loss.Name(), err)
+		}
+		if math.Abs(value-testCase.expected) > acceptedError {
+			t.Errorf("%s: expected value to be %v, got %v", testCase.loss.Name(), testCase.expected, value)
+		}
+	}
+}
+
+func TestBinaryCrossEntropyValue(t *testing.T) {
+	expected := newMatrix(t, 2, 1, []float64{1, 0})
+	predicted := newMatrix(t, 2, 1, []float64{0.8, 0.4})
+
+	value, err := loss.NewBinaryCrossEntropy().Value(expected, predicted)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	if expectedValue := -(math.Log(0.8) + math.Log(0.6)) / 2; math.Abs(value-expectedValue) > acceptedError {
+		t.Errorf("expected value to be %v, got %v", expectedValue, value)
+	}
+}
+
+func TestSoftmaxCrossEntropy(t *testing.T) {
+	expected := newMatrix(t, 1, 3, []float64{0, 1, 0})
+	logits := newMatrix(t, 1, 3, []float64{1, 2, 3})
+	sumOfExps := math.Exp(1) + math.Exp(2) + math.Exp(3)
+
+	value, err := loss.NewSoftmaxCrossEntropy().Value(expected, logits)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if expectedValue := -math.Log(math.Exp(2) / sumOfExps); math.Abs(value-expectedValue) > acceptedError {
+		t.Errorf("expected value to be %v, got %v", expectedValue, value)
+	}
+
+	gradient, err := loss.NewSoftmaxCrossEntropy().Gradient(expected, logits)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	expectedGradient := []float64{math.Exp(1) / sumOfExps, math.Exp(2)/sumOfExps - 1, math.Exp(3) / sumOfExps}
+	for i, value := range gradient.FlattenedElements() {
+		if math.Abs(value-expectedGradient[i]) > acceptedError {
+			t.Errorf("expected gradient element %d to be %v, got %v", i, expectedGradient[i], value)
+		}
+	}
+}
+
+func TestSoftmaxCrossEntropyWithLargeLogits(t *testing.T) {
+	expected := newMatrix(t, 1, 2, []float64{1, 0})
+	logits := newMatrix(t, 1, 2, []float64{1000, -1000})
+
+	value, err := loss.NewSoftmaxCrossEntropy().Value(expected, logits)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) || value > acceptedError {
+		t.Errorf("expected value to be close to 0, got %v", value)
+	}
+}
+
+func TestCategoricalCrossEntropy(t *testing.T) {
+	expected := newMatrix(t, 2, 3, []float64{0, 1, 0, 1, 0, 0})
+	probabilities := newMatrix(t, 2, 3, []float64{0.2, 0.5, 0.3, 0.1, 0.6, 0.3})
+
+	value, err := loss.NewCategoricalCrossEntropy().Value(expected, probabilities)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if expectedValue := -(math.Log(0.5) + math.Log(0.1)) / 2; math.Abs(value-expectedValue) > acceptedError {
+		t.Errorf("expected value to be %v, got %v", expectedValue, value)
+	}
+}
+
+func TestCategoricalCrossEntropyWithZeroProbability(t *testing.T) {
+	expected := newMatrix(t, 1, 2, []float64{1, 0})
+	probabilities := newMatrix(t, 1, 2, []float64{0, 1})
+
+	value, err := loss.NewCategoricalCrossEntropy().Value(expected, probabilities)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		t.Errorf("expected value to be finite, got %v", value)
+	}
+}
+
+// Gradient must be the derivative of the loss of each sample, which
+// is Value times the amount of samples.
+func TestLossGradientsMatchNumericalGradients(t *testing.T) {
+	huber, err := loss.NewHuber(0.5)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	losses := []loss.Loss{
+		loss.NewMSE(),
+		loss.NewMAE(),
+		huber,
+		loss.NewLogCosh(),
+		loss.NewBinaryCrossEntropy(),
+		loss.NewSoftmaxCrossEntropy(),
+		loss.NewCategoricalCrossEntropy(),
+	}
+	expectedValues := []float64{1, 0, 0, 0, 0, 1}
+	predictedValues := []float64{0.3, 0.2, 0.9, 0.6, 0.25, 0.7}
+	rows, columns := 2, 3
+	expected := newMatrix(t, rows, columns, expectedValues)
+	smallDifference := 1e-6
+	for _, l := range losses {
+		gradient, err := l.Gradient(expected, newMatrix(t, rows, columns, predictedValues))
+		if err != nil {
+			t.Errorf("%s: expected err to be nil, got %v", l.Name(), err)
+			continue
+		}
+		for i, analytical := range gradient.FlattenedElements() {
+			right := append([]float64{}, predictedValues...)
+			right[i] += smallDifference
+			left := append([]float64{}, predictedValues...)
+			left[i] -= smallDifference
+			valueAtRight, _ := l.Value(expected, newMatrix(t, rows, columns, right))
+			valueAtLeft, _ := l.Value(expected, newMatrix(t, rows, columns, left))
+			numerical := float64(rows) * (valueAtRight - valueAtLeft) / (2 * smallDifference)
+			if math.Abs(numerical-analytical) > 1e-4 {
+				t.Errorf("%s: expected gradient element %d to be %v, got %v", l.Name(), i, numerical, analytical)
+			}
+		}
+	}
+}
+
+func TestByName(t *testing.T) {
+	for _, name := range []string{"mse", "mae", "huber", "log_cosh", "binary_cross_entropy", "softmax_cross_entropy", "categorical_cross_entropy"} {
+		l, err := loss.ByName(name, 0.5)
+		if err != nil {
+			t.Errorf("%s: expected err to be nil, got %v", name, err)
+			continue
+		}
+		if l.Name() != name {
+			t.Errorf("expected loss to be %s, got %s", name, l.Name())
+		}
+	}
+	if _, err := loss.ByName("unknown", 0); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+}
+
+func TestLossWithDifferentShapes(t *testing.T) {
+	expected := newMatrix(t, 2, 1, []float64{1, 0})
+	predicted := newMatrix(t, 1, 2, []float64{1, 0})
+	if _, err := loss.NewMSE().Value(expected, predicted); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+	if _, err := loss.NewMSE().Gradient(expected, predicted); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+}
+
+func TestGradientIntoMatchesGradient(t *testing.T) {
+	expected := newMatrix(t, 2, 3, []float64{1, 0, 0, 0, 0.3, 0.7})
+	predicted := newMatrix(t, 2, 3, []float64{0.6, 0.3, 0.1, 0.2, 0.5, 0.3})
+	for _, name := range []string{"mse", "mae", "huber", "log_cosh", "binary_cross_entropy", "softmax_cross_entropy", "categorical_cross_entropy"} {
+		l, err := loss.ByName(name, 0.5)
+		if err != nil {
+			t.Fatalf("%s: expected err to be nil, got %v", name, err)
+		}
+		gradient, err := l.Gradient(expected, predicted)
+		if err != nil {
+			t.Fatalf("%s: expected err to be nil, got %v", name, err)
+		}
+		// the destination keeps values of a previous batch
+		dst := newMatrix(t, 2, 3, []float64{9, 9, 9, 9, 9, 9})
+
+		if err := l.(loss.GradientWriter).GradientInto(dst, expected, predicted); err != nil {
+			t.Fatalf("%s: expected err to be nil, got %v", name, err)
+		}
+
+		received := dst.FlattenedElements()
+		for i, value := range gradient.FlattenedElements() {
+			if received[i] != value {
+				t.Errorf("%s: expected gradient element %d to be %v, got %v", name, i, value, received[i])
+			}
+		}
+		if err := l.(loss.GradientWriter).GradientInto(newMatrix(t, 3, 2, make([]float64, 6)), expected, predicted); err == nil {
+			t.Errorf("%s: expected err to be not nil", name)
+		}
+	}
+}
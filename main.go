@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
 
 	"github.com/buarki/supervised-machine-learning/activation"
 	"github.com/buarki/supervised-machine-learning/data"
+	"github.com/buarki/supervised-machine-learning/metric"
 	"github.com/buarki/supervised-machine-learning/neuralnet"
+	"github.com/buarki/supervised-machine-learning/optimizer"
 )
 
 func main() {
@@ -28,17 +34,57 @@ func main() {
 	traningBatch := normalized[:sizeOfBatchToTrain]
 	validationBatch := normalized[sizeOfBatchToTrain:]
 
-	learningRate := 0.001
-	regularizationFactor := 0.0001
-	nn, err := neuralnet.New(learningRate, regularizationFactor, activation.Sigmoid, activation.SigmoidPrime)
+	adam, err := optimizer.NewAdam(0.9, 0.999, 1e-8)
+	if err != nil {
+		log.Fatalf("failed to create optimizer, got %v", err)
+	}
+	nn, err := neuralnet.New(
+		neuralnet.WithLayers(2, 3, 1),
+		neuralnet.WithLearningRate(0.01),
+		neuralnet.WithRegularizer(0.0001),
+		neuralnet.WithActivation(activation.NewSigmoid()),
+		neuralnet.WithOptimizer(adam),
+	)
 	if err != nil {
 		log.Fatalf("failed to create neural network, got %v", err)
 	}
 
-	trainingEpochs := 600_000
-	if err := neuralnet.Train(nn, trainingEpochs, traningBatch); err != nil {
+	// early stopping ends the train well before the max epochs, and
+	// ctrl+c stops it keeping what was learned so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	maxTrainingEpochs := 100_000
+	history, err := neuralnet.TrainContext(ctx, nn, maxTrainingEpochs, traningBatch,
+		neuralnet.WithValidation(validationBatch, 10),
+		neuralnet.WithMetrics(metric.NewRMSE()),
+		neuralnet.WithEarlyStopping(200, 1e-7),
+		neuralnet.WithCallbacks(neuralnet.NewProgressLogger(false)),
+	)
+	if errors.Is(err, context.Canceled) {
+		modelPath := "model.json"
+		log.Printf("%v, saving model on %s", err, modelPath)
+		if err := nn.Save(modelPath); err != nil {
+			log.Fatalf("failed to save model, got %v", err)
+		}
+	} else if err != nil {
 		log.Fatalf("failed to train neural net, got %v", err)
 	}
+	historyPath := "history.csv"
+	historyFile, err := os.Create(historyPath)
+	if err != nil {
+		log.Fatalf("failed to create %s, got %v", historyPath, err)
+	}
+	if err := history.WriteCSV(historyFile); err != nil {
+		log.Fatalf("failed to write training history, got %v", err)
+	}
+	if err := historyFile.Close(); err != nil {
+		log.Fatalf("failed to close %s, got %v", historyPath, err)
+	}
+	validation, err := nn.Validate(validationBatch, metric.NewRMSE())
+	if err != nil {
+		log.Fatalf("failed to validate neural net, got %v", err)
+	}
+	fmt.Printf("validation loss %.7f, rmse %.7f\n", validation.Loss, validation.Metrics["rmse"])
 
 	fmt.Println("===============")
 	fmt.Println("===============")
@@ -0,0 +1,166 @@
+package matrix
+
+import "fmt"
+
+// Broadcasting follows NumPy: two matrices can be operated when each of
+// their dimensions is either equal or 1 on one of them, and the one with
+// size 1 is repeated along it. So a row vector is applied to every row,
+// a column vector to every column and a 1x1 matrix to every element.
+
+// Broadcast applies operation between the elements of this matrix
+// and the ones of a broadcasting them, and returns the result matrix
+// if their shapes allow it.
+func (m *Matrix) Broadcast(a *Matrix, operation ElementWiseOperation) (*Matrix, error) {
+	if m == nil || a == nil {
+		return nil, fmt.Errorf("given matrix is nil")
+	}
+	rows, columns, err := broadcastShape(m, a)
+	if err != nil {
+		return nil, err
+	}
+	resultMatrix, err := emptyMatrix(rows, columns)
+	if err != nil {
+		return nil, err
+	}
+	if err := BroadcastInto(resultMatrix, m, a, operation); err != nil {
+		return nil, err
+	}
+	return resultMatrix, nil
+}
+
+// BroadcastAdd sums a to this matrix broadcasting them.
+func (m *Matrix) BroadcastAdd(a *Matrix) (*Matrix, error) {
+	return m.Broadcast(a, ElementWiseOperationSum)
+}
+
+// BroadcastSub subtracts a from this matrix broadcasting them.
+func (m *Matrix) BroadcastSub(a *Matrix) (*Matrix, error) {
+	return m.Broadcast(a, ElementWiseOperationSubtraction)
+}
+
+// BroadcastMul multiplies this matrix by a element wise broadcasting them.
+func (m *Matrix) BroadcastMul(a *Matrix) (*Matrix, error) {
+	return m.Broadcast(a, ElementWiseOperationMultiplication)
+}
+
+// BroadcastDiv divides this matrix by a element wise broadcasting them.
+func (m *Matrix) BroadcastDiv(a *Matrix) (*Matrix, error) {
+	return m.Broadcast(a, ElementWiseOperationDivision)
+}
+
+// BroadcastInto writes on dst the operation between the elements of a
+// and the ones of b broadcasting them. dst may be the operand which
+// already has the shape of the result.
+func BroadcastInto(dst, a, b *Matrix, operation ElementWiseOperation) error {
+	kernel, err := elementWiseKernel(operation)
+	if err != nil {
+		return err
+	}
+	if a == nil || b == nil {
+		return fmt.Errorf("given matrix is nil")
+	}
+	rows, columns, err := broadcastShape(a, b)
+	if err != nil {
+		return err
+	}
+	if err := checkDestination(dst, rows, columns); err != nil {
+		return err
+	}
+	withScalarLeft, withScalarRight := scalarKernels(operation)
+	for i := 0; i < rows; i++ {
+		dstRow, aRow, bRow := dst.row(i), a.row(broadcastIndex(a.Rows, i)), b.row(broadcastIndex(b.Rows, i))
+		switch {
+		case a.Columns == b.Columns:
+			kernel(dstRow, aRow, bRow)
+		case a.Columns == 1:
+			withScalarLeft(dstRow, aRow[0], bRow)
+		default:
+			withScalarRight(dstRow, aRow, bRow[0])
+		}
+	}
+	return nil
+}
+
+func broadcastShape(a, b *Matrix) (int, int, error) {
+	rows, rowsOk := broadcastDimension(a.Rows, b.Rows)
+	columns, columnsOk := broadcastDimension(a.Columns, b.Columns)
+	if !rowsOk || !columnsOk {
+		return 0, 0, fmt.Errorf("matrices cannot be broadcast: this has (%d x %d) while given one has (%d x %d)", a.Rows, a.Columns, b.Rows, b.Columns)
+	}
+	return rows, columns, nil
+}
+
+func broadcastDimension(a, b int) (int, bool) {
+	switch {
+	case a == b || b == 1:
+		return a, true
+	case a == 1:
+		return b, true
+	}
+	return 0, false
+}
+
+// broadcastIndex maps the index i of the result
+// on a dimension of the given size.
+func broadcastIndex(size, i int) int {
+	if size == 1 {
+		return 0
+	}
+	return i
+}
+
+// scalarKernels returns the loops applying operation between a single
+// value and a slice, with the value as the left or right operand.
+func scalarKernels(operation ElementWiseOperation) (func(dst []float64, a float64, b []float64), func(dst, a []float64, b float64)) {
+	switch operation {
+	case ElementWiseOperationSum:
+		return func(dst []float64, a float64, b []float64) { addScalarKernel(dst, b, a) }, addScalarKernel
+	case ElementWiseOperationSubtraction:
+		return scalarSubKernel, subScalarKernel
+	case ElementWiseOperationMultiplication:
+		return func(dst []float64, a float64, b []float64) { mulScalarKernel(dst, b, a) }, mulScalarKernel
+	}
+	return scalarDivKernel, divScalarKernel
+}
+
+func addScalarKernel(dst, a []float64, b float64) {
+	a = a[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] + b
+	}
+}
+
+func subScalarKernel(dst, a []float64, b float64) {
+	a = a[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] - b
+	}
+}
+
+func scalarSubKernel(dst []float64, a float64, b []float64) {
+	b = b[:len(dst)]
+	for i := range dst {
+		dst[i] = a - b[i]
+	}
+}
+
+func mulScalarKernel(dst, a []float64, b float64) {
+	a = a[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] * b
+	}
+}
+
+func divScalarKernel(dst, a []float64, b float64) {
+	a = a[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] / b
+	}
+}
+
+func scalarDivKernel(dst []float64, a float64, b []float64) {
+	b = b[:len(dst)]
+	for i := range dst {
+		dst[i] = a / b[i]
+	}
+}
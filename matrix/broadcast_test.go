@@ -0,0 +1,92 @@
+package matrix_test
+
+import (
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+func TestBroadcast(t *testing.T) {
+	m := newMatrix(t, 2, 3, []float64{1, 2, 3, 4, 5, 6})
+	rowVector := newMatrix(t, 1, 3, []float64{10, 20, 30})
+	columnVector := newMatrix(t, 2, 1, []float64{2, 4})
+	scalar := newMatrix(t, 1, 1, []float64{2})
+	testCases := []struct {
+		name     string
+		result   func() (*matrix.Matrix, error)
+		expected *matrix.Matrix
+	}{
+		{"add row vector", func() (*matrix.Matrix, error) { return m.BroadcastAdd(rowVector) }, newMatrix(t, 2, 3, []float64{11, 22, 33, 14, 25, 36})},
+		{"sub row vector", func() (*matrix.Matrix, error) { return m.BroadcastSub(rowVector) }, newMatrix(t, 2, 3, []float64{-9, -18, -27, -6, -15, -24})},
+		{"row vector minus matrix", func() (*matrix.Matrix, error) { return rowVector.BroadcastSub(m) }, newMatrix(t, 2, 3, []float64{9, 18, 27, 6, 15, 24})},
+		{"mul column vector", func() (*matrix.Matrix, error) { return m.BroadcastMul(columnVector) }, newMatrix(t, 2, 3, []float64{2, 4, 6, 16, 20, 24})},
+		{"div column vector", func() (*matrix.Matrix, error) { return m.BroadcastDiv(columnVector) }, newMatrix(t, 2, 3, []float64{0.5, 1, 1.5, 1, 1.25, 1.5})},
+		{"column vector divided by matrix", func() (*matrix.Matrix, error) { return columnVector.BroadcastDiv(m) }, newMatrix(t, 2, 3, []float64{2, 1, 2.0 / 3, 1, 0.8, 4.0 / 6})},
+		{"add scalar", func() (*matrix.Matrix, error) { return scalar.BroadcastAdd(m) }, newMatrix(t, 2, 3, []float64{3, 4, 5, 6, 7, 8})},
+		{"sub scalar", func() (*matrix.Matrix, error) { return m.BroadcastSub(scalar) }, newMatrix(t, 2, 3, []float64{-1, 0, 1, 2, 3, 4})},
+		{"column and row vectors", func() (*matrix.Matrix, error) { return columnVector.BroadcastMul(rowVector) }, newMatrix(t, 2, 3, []float64{20, 40, 60, 40, 80, 120})},
+		{"same shape", func() (*matrix.Matrix, error) { return m.BroadcastAdd(m) }, newMatrix(t, 2, 3, []float64{2, 4, 6, 8, 10, 12})},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.result()
+			if err != nil {
+				t.Fatalf("expected err to be nil, got %v", err)
+			}
+
+			if result.Rows != tc.expected.Rows || result.Columns != tc.expected.Columns {
+				t.Fatalf("expected result to be (%dx%d), got (%dx%d)", tc.expected.Rows, tc.expected.Columns, result.Rows, result.Columns)
+			}
+			ensureElements(t, result, tc.expected.FlattenedElements())
+		})
+	}
+}
+
+func TestBroadcastIntoOperandView(t *testing.T) {
+	m := newMatrix(t, 3, 3, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9})
+	view, err := m.Slice(1, 3, 0, 2)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	if err := matrix.BroadcastInto(view, view, newMatrix(t, 2, 1, []float64{10, 100}), matrix.ElementWiseOperationMultiplication); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	ensureElements(t, m, []float64{1, 2, 3, 40, 50, 6, 700, 800, 9})
+}
+
+func TestBroadcastWithIncompatibleShapes(t *testing.T) {
+	m := newMatrix(t, 2, 3, []float64{1, 2, 3, 4, 5, 6})
+	testCases := []struct {
+		name      string
+		operation func() error
+	}{
+		{"different columns", func() error {
+			_, err := m.BroadcastAdd(newMatrix(t, 1, 2, []float64{1, 2}))
+			return err
+		}},
+		{"different rows", func() error {
+			_, err := m.BroadcastAdd(newMatrix(t, 3, 1, []float64{1, 2, 3}))
+			return err
+		}},
+		{"nil matrix", func() error {
+			_, err := m.BroadcastAdd(nil)
+			return err
+		}},
+		{"unsupported operation", func() error {
+			_, err := m.Broadcast(m, matrix.ElementWiseOperation(42))
+			return err
+		}},
+		{"destination with the shape of an operand", func() error {
+			return matrix.BroadcastInto(newMatrix(t, 1, 3, make([]float64, 3)), m, newMatrix(t, 1, 3, make([]float64, 3)), matrix.ElementWiseOperationSum)
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.operation(); err == nil {
+				t.Errorf("expected err to be not nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package matrix
+
+import "fmt"
+
+// ElementWiseOperation is an operation between the elements
+// at the same position of two matrices with the same shape.
+type ElementWiseOperation int
+
+const (
+	ElementWiseOperationSum ElementWiseOperation = iota
+	ElementWiseOperationSubtraction
+	ElementWiseOperationMultiplication
+	ElementWiseOperationDivision
+)
+
+func (o ElementWiseOperation) String() string {
+	switch o {
+	case ElementWiseOperationSum:
+		return "+"
+	case ElementWiseOperationSubtraction:
+		return "-"
+	case ElementWiseOperationMultiplication:
+		return "*"
+	case ElementWiseOperationDivision:
+		return "/"
+	}
+	return fmt.Sprintf("ElementWiseOperation(%d)", int(o))
+}
+
+// ElementWise applies operation between the elements of this matrix
+// and the ones of a and returns the result matrix if it can be done.
+func (m *Matrix) ElementWise(a *Matrix, operation ElementWiseOperation) (*Matrix, error) {
+	if err := checkSameShape(m, a); err != nil {
+		return nil, err
+	}
+	resultMatrix, err := emptyMatrix(m.Rows, m.Columns)
+	if err != nil {
+		return nil, err
+	}
+	if err := ElementWiseInto(resultMatrix, m, a, operation); err != nil {
+		return nil, err
+	}
+	return resultMatrix, nil
+}
+
+// ElementWiseInto writes on dst the operation between
+// the elements of a and the ones of b.
+func ElementWiseInto(dst, a, b *Matrix, operation ElementWiseOperation) error {
+	kernel, err := elementWiseKernel(operation)
+	if err != nil {
+		return err
+	}
+	if err := checkSameShape(a, b); err != nil {
+		return err
+	}
+	if err := checkDestination(dst, a.Rows, a.Columns); err != nil {
+		return err
+	}
+	if dst.Contiguous() && a.Contiguous() && b.Contiguous() {
+		size := a.Rows * a.Columns
+		kernel(dst.data[:size], a.data[:size], b.data[:size])
+		return nil
+	}
+	for i := 0; i < a.Rows; i++ {
+		kernel(dst.row(i), a.row(i), b.row(i))
+	}
+	return nil
+}
+
+// elementWiseKernel returns the loop applying operation on slices
+// of the same length. They are resliced to the length of dst so
+// the compiler can drop the bounds checks inside the loop.
+func elementWiseKernel(operation ElementWiseOperation) (func(dst, a, b []float64), error) {
+	switch operation {
+	case ElementWiseOperationSum:
+		return addKernel, nil
+	case ElementWiseOperationSubtraction:
+		return subKernel, nil
+	case ElementWiseOperationMultiplication:
+		return mulKernel, nil
+	case ElementWiseOperationDivision:
+		return divKernel, nil
+	}
+	return nil, fmt.Errorf("unsupported element wise operation %v", operation)
+}
+
+func addKernel(dst, a, b []float64) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] + b[i]
+	}
+}
+
+func subKernel(dst, a, b []float64) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] - b[i]
+	}
+}
+
+func mulKernel(dst, a, b []float64) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] * b[i]
+	}
+}
+
+func divKernel(dst, a, b []float64) {
+	a, b = a[:len(dst)], b[:len(dst)]
+	for i := range dst {
+		dst[i] = a[i] / b[i]
+	}
+}
@@ -0,0 +1,100 @@
+package matrix_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+func TestElementWise(t *testing.T) {
+	a := newMatrix(t, 2, 2, []float64{1, 2, 3, 4})
+	b := newMatrix(t, 2, 2, []float64{4, 2, -1, 8})
+	testCases := []struct {
+		operation matrix.ElementWiseOperation
+		expected  []float64
+	}{
+		{matrix.ElementWiseOperationSum, []float64{5, 4, 2, 12}},
+		{matrix.ElementWiseOperationSubtraction, []float64{-3, 0, 4, -4}},
+		{matrix.ElementWiseOperationMultiplication, []float64{4, 4, -3, 32}},
+		{matrix.ElementWiseOperationDivision, []float64{0.25, 1, -3, 0.5}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.operation.String(), func(t *testing.T) {
+			result, err := a.ElementWise(b, tc.operation)
+			if err != nil {
+				t.Fatalf("expected err to be nil, got %v", err)
+			}
+
+			ensureElements(t, result, tc.expected)
+		})
+	}
+}
+
+func TestElementWiseIntoViews(t *testing.T) {
+	m := newMatrix(t, 3, 3, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9})
+	left, err := m.Slice(0, 3, 0, 2)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	right, err := m.Slice(0, 3, 1, 3)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	dst := newMatrix(t, 3, 2, make([]float64, 6))
+
+	if err := matrix.ElementWiseInto(dst, right, left, matrix.ElementWiseOperationDivision); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	ensureElements(t, dst, []float64{2, 1.5, 5.0 / 4, 6.0 / 5, 8.0 / 7, 9.0 / 8})
+}
+
+func TestElementWiseWithInvalidArguments(t *testing.T) {
+	a := newMatrix(t, 2, 2, []float64{1, 2, 3, 4})
+	b := newMatrix(t, 2, 1, []float64{1, 2})
+	testCases := []struct {
+		name      string
+		operation func() error
+	}{
+		{"unsupported operation", func() error {
+			_, err := a.ElementWise(a, matrix.ElementWiseOperation(42))
+			return err
+		}},
+		{"different shapes", func() error {
+			_, err := a.ElementWise(b, matrix.ElementWiseOperationDivision)
+			return err
+		}},
+		{"nil matrix", func() error {
+			_, err := a.ElementWise(nil, matrix.ElementWiseOperationSum)
+			return err
+		}},
+		{"destination with wrong shape", func() error {
+			return matrix.ElementWiseInto(b, a, a, matrix.ElementWiseOperationSum)
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.operation(); err == nil {
+				t.Errorf("expected err to be not nil")
+			}
+		})
+	}
+}
+
+func BenchmarkElementWise(b *testing.B) {
+	random := rand.New(rand.NewSource(1))
+	for _, size := range []int{16, 256} {
+		m := randomMatrix(b, random, size, size)
+		a := randomMatrix(b, random, size, size)
+		dst := randomMatrix(b, random, size, size)
+		b.Run(fmt.Sprintf("%dx%d", size, size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := matrix.ElementWiseInto(dst, m, a, matrix.ElementWiseOperationMultiplication); err != nil {
+					b.Fatalf("expected err to be nil, got %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package matrix
+
+import "fmt"
+
+// The operations below write their result on a matrix given by the caller
+// instead of allocating a new one, so the same matrices can be reused over
+// and over. Element wise ones accept the destination being an operand.
+
+// AddInPlace sums a to this matrix.
+func (m *Matrix) AddInPlace(a *Matrix) error {
+	return AddInto(m, m, a)
+}
+
+// SubInPlace subtracts a from this matrix.
+func (m *Matrix) SubInPlace(a *Matrix) error {
+	return SubInto(m, m, a)
+}
+
+// HadamardInPlace multiplies each element of this matrix by the one of a.
+func (m *Matrix) HadamardInPlace(a *Matrix) error {
+	return HadamardInto(m, m, a)
+}
+
+// AddScaledInPlace sums factor*a to this matrix.
+func (m *Matrix) AddScaledInPlace(a *Matrix, factor float64) error {
+	if err := checkSameShape(m, a); err != nil {
+		return err
+	}
+	for i := 0; i < m.Rows; i++ {
+		row, aRow := m.row(i), a.row(i)
+		for j, aValue := range aRow {
+			row[j] += factor * aValue
+		}
+	}
+	return nil
+}
+
+// ScaleInPlace multiplies every element of this matrix by factor.
+func (m *Matrix) ScaleInPlace(factor float64) {
+	for i := 0; i < m.Rows; i++ {
+		row := m.row(i)
+		for j := range row {
+			row[j] *= factor
+		}
+	}
+}
+
+// ApplyInPlace replaces every element of this matrix by f of it.
+func (m *Matrix) ApplyInPlace(f func(value float64) float64) error {
+	return ApplyInto(m, m, f)
+}
+
+// SetFlattenedElements copies values, which holds the elements
+// row after row, onto this matrix.
+func (m *Matrix) SetFlattenedElements(values []float64) error {
+	if len(values) != m.Rows*m.Columns {
+		return fmt.Errorf("provided array cannot be arranged on a matrix of size %dx%d", m.Rows, m.Columns)
+	}
+	for i := 0; i < m.Rows; i++ {
+		copy(m.row(i), values[i*m.Columns:(i+1)*m.Columns])
+	}
+	return nil
+}
+
+// AddInto writes a + b on dst.
+func AddInto(dst, a, b *Matrix) error {
+	return ElementWiseInto(dst, a, b, ElementWiseOperationSum)
+}
+
+// SubInto writes a - b on dst.
+func SubInto(dst, a, b *Matrix) error {
+	return ElementWiseInto(dst, a, b, ElementWiseOperationSubtraction)
+}
+
+// HadamardInto writes the Hadamard product of a and b on dst.
+func HadamardInto(dst, a, b *Matrix) error {
+	return ElementWiseInto(dst, a, b, ElementWiseOperationMultiplication)
+}
+
+// CopyInto copies the elements of a onto dst.
+func CopyInto(dst, a *Matrix) error {
+	if a == nil {
+		return fmt.Errorf("given matrix is nil")
+	}
+	if err := checkDestination(dst, a.Rows, a.Columns); err != nil {
+		return err
+	}
+	for i := 0; i < a.Rows; i++ {
+		copy(dst.row(i), a.row(i))
+	}
+	return nil
+}
+
+// ApplyInto writes f of each element of a on dst.
+func ApplyInto(dst, a *Matrix, f func(value float64) float64) error {
+	if f == nil {
+		return fmt.Errorf("function to be applied element wise must be passed")
+	}
+	if a == nil {
+		return fmt.Errorf("given matrix is nil")
+	}
+	if err := checkDestination(dst, a.Rows, a.Columns); err != nil {
+		return err
+	}
+	if dst.Contiguous() && a.Contiguous() {
+		size := a.Rows * a.Columns
+		applyKernel(dst.data[:size], a.data[:size], f)
+		return nil
+	}
+	for i := 0; i < a.Rows; i++ {
+		applyKernel(dst.row(i), a.row(i), f)
+	}
+	return nil
+}
+
+func applyKernel(dst, a []float64, f func(value float64) float64) {
+	a = a[:len(dst)]
+	for i := range dst {
+		dst[i] = f(a[i])
+	}
+}
+
+// AddRowVectorInto writes on dst the row vector v summed to every row of a.
+func AddRowVectorInto(dst, a, v *Matrix) error {
+	if a == nil || v == nil {
+		return fmt.Errorf("given matrix is nil")
+	}
+	if v.Rows != 1 || v.Columns != a.Columns {
+		return fmt.Errorf("given matrix is not a row vector matching this matrix columns: this has (%d x %d) while given one has (%d x %d)", a.Rows, a.Columns, v.Rows, v.Columns)
+	}
+	return BroadcastInto(dst, a, v, ElementWiseOperationSum)
+}
+
+// SumColumnsInto writes the sum of the elements of each column of a
+// on dst, which must be a row vector not sharing its storage with a.
+func SumColumnsInto(dst, a *Matrix) error {
+	if a == nil {
+		return fmt.Errorf("given matrix is nil")
+	}
+	if err := checkDestination(dst, 1, a.Columns); err != nil {
+		return err
+	}
+	if sharesStorage(dst, a) {
+		return fmt.Errorf("destination matrix cannot share storage with the given one")
+	}
+	sums := dst.row(0)
+	for j := range sums {
+		sums[j] = 0
+	}
+	for i := 0; i < a.Rows; i++ {
+		for j, value := range a.row(i) {
+			sums[j] += value
+		}
+	}
+	return nil
+}
+
+// TransposeInto writes the transpose of a on dst,
+// which cannot share its storage.
+func TransposeInto(dst, a *Matrix) error {
+	if a == nil {
+		return fmt.Errorf("given matrix is nil")
+	}
+	if err := checkDestination(dst, a.Columns, a.Rows); err != nil {
+		return err
+	}
+	if sharesStorage(dst, a) {
+		return fmt.Errorf("destination matrix cannot share storage with the given one")
+	}
+	for i := 0; i < a.Rows; i++ {
+		for j, value := range a.row(i) {
+			dst.data[j*dst.stride+i] = value
+		}
+	}
+	return nil
+}
+
+// MulInto writes the dot product a.b on dst, which cannot share
+// its storage with a or b. Large products are split across
+// goroutines as by DotProductWith.
+func MulInto(dst, a, b *Matrix) error {
+	if a == nil || b == nil {
+		return fmt.Errorf("given matrix is nil")
+	}
+	if a.Columns != b.Rows {
+		return fmt.Errorf("dot product not possible due to matrix dimensions, this has shape (%dx%d), given has (%dx%d)", a.Rows, a.Columns, b.Rows, b.Columns)
+	}
+	if err := checkDestination(dst, a.Rows, b.Columns); err != nil {
+		return err
+	}
+	if sharesStorage(dst, a) || sharesStorage(dst, b) {
+		return fmt.Errorf("destination matrix cannot share storage with the given ones")
+	}
+	for i := 0; i < dst.Rows; i++ {
+		row := dst.row(i)
+		for j := range row {
+			row[j] = 0
+		}
+	}
+	multiply(dst, a, b)
+	return nil
+}
+
+func checkSameShape(m, a *Matrix) error {
+	if m == nil || a == nil {
+		return fmt.Errorf("given matrix is nil")
+	}
+	if m.Rows != a.Rows || m.Columns != a.Columns {
+		return fmt.Errorf("matrices have different dimensions: this has (%d x %d) while given one has (%d x %d)", m.Rows, m.Columns, a.Rows, a.Columns)
+	}
+	return nil
+}
+
+func checkDestination(dst *Matrix, rows, columns int) error {
+	if dst == nil {
+		return fmt.Errorf("destination matrix is nil")
+	}
+	if dst.Rows != rows || dst.Columns != columns {
+		return fmt.Errorf("destination matrix has shape (%dx%d), expected (%dx%d)", dst.Rows, dst.Columns, rows, columns)
+	}
+	return nil
+}
+
+// sharesStorage tells whether both matrices are backed by the same
+// array, as views of the same matrix are. Slices of the same array
+// end on the same element once extended to their capacity.
+func sharesStorage(a, b *Matrix) bool {
+	return &a.data[:cap(a.data)][cap(a.data)-1] == &b.data[:cap(b.data)][cap(b.data)-1]
+}
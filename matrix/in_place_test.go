@@ -0,0 +1,208 @@
+package matrix_test
+
+import (
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+func newMatrix(t *testing.T, rows, columns int, data []float64) *matrix.Matrix {
+	t.Helper()
+	m, err := matrix.New(rows, columns, data)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	return m
+}
+
+func TestInPlaceOperations(t *testing.T) {
+	a := newMatrix(t, 2, 2, []float64{1, 2, 3, 4})
+	testCases := []struct {
+		name      string
+		operation func(m *matrix.Matrix) error
+		expected  []float64
+	}{
+		{"add", func(m *matrix.Matrix) error { return m.AddInPlace(a) }, []float64{3, 4, 5, 6}},
+		{"sub", func(m *matrix.Matrix) error { return m.SubInPlace(a) }, []float64{1, 0, -1, -2}},
+		{"hadamard", func(m *matrix.Matrix) error { return m.HadamardInPlace(a) }, []float64{2, 4, 6, 8}},
+		{"add scaled", func(m *matrix.Matrix) error { return m.AddScaledInPlace(a, -0.5) }, []float64{1.5, 1, 0.5, 0}},
+		{"scale", func(m *matrix.Matrix) error { m.ScaleInPlace(3); return nil }, []float64{6, 6, 6, 6}},
+		{"apply", func(m *matrix.Matrix) error {
+			return m.ApplyInPlace(func(value float64) float64 { return value * value })
+		}, []float64{4, 4, 4, 4}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newMatrix(t, 2, 2, []float64{2, 2, 2, 2})
+
+			if err := tc.operation(m); err != nil {
+				t.Fatalf("expected err to be nil, got %v", err)
+			}
+
+			ensureElements(t, m, tc.expected)
+		})
+	}
+}
+
+func TestInPlaceOperationsOnViews(t *testing.T) {
+	m := newMatrix(t, 3, 3, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9})
+	view, err := m.Slice(1, 3, 1, 3)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	if err := view.AddInPlace(newMatrix(t, 2, 2, []float64{10, 20, 30, 40})); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	view.ScaleInPlace(2)
+
+	ensureElements(t, m, []float64{1, 2, 3, 4, 30, 52, 7, 76, 98})
+}
+
+func TestInPlaceOperationsWithDifferentShapes(t *testing.T) {
+	m := newMatrix(t, 2, 2, []float64{1, 2, 3, 4})
+	a := newMatrix(t, 1, 2, []float64{1, 2})
+
+	if err := m.AddInPlace(a); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+	if err := m.AddScaledInPlace(a, 2); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+	if err := m.ApplyInPlace(nil); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+	ensureElements(t, m, []float64{1, 2, 3, 4})
+}
+
+func TestSetFlattenedElements(t *testing.T) {
+	m := newMatrix(t, 2, 3, []float64{1, 2, 3, 4, 5, 6})
+	view, err := m.Slice(0, 2, 1, 3)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	if err := view.SetFlattenedElements([]float64{-1, -2, -3, -4}); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	ensureElements(t, m, []float64{1, -1, -2, 4, -3, -4})
+	if err := view.SetFlattenedElements([]float64{1, 2}); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+}
+
+func TestIntoOperationsMatchAllocatingOnes(t *testing.T) {
+	a := newMatrix(t, 2, 3, []float64{1, -2, 3, 4, 5, -6})
+	b := newMatrix(t, 2, 3, []float64{0.5, 2, -1, 3, 0, 2})
+	v := newMatrix(t, 1, 3, []float64{10, 20, 30})
+	square := func(value float64) float64 { return value * value }
+
+	sum, _ := a.SumWith(b)
+	difference, _ := a.Minus(b)
+	hadamard, _ := a.HadamardProductWith(b)
+	applied, _ := a.ApplyElementWise(square)
+	withRowVector, _ := a.SumWithRowVector(v)
+	product, _ := a.DotProductWith(b.T())
+	testCases := []struct {
+		name     string
+		dst      *matrix.Matrix
+		into     func(dst *matrix.Matrix) error
+		expected *matrix.Matrix
+	}{
+		{"add", newMatrix(t, 2, 3, make([]float64, 6)), func(dst *matrix.Matrix) error { return matrix.AddInto(dst, a, b) }, sum},
+		{"sub", newMatrix(t, 2, 3, make([]float64, 6)), func(dst *matrix.Matrix) error { return matrix.SubInto(dst, a, b) }, difference},
+		{"hadamard", newMatrix(t, 2, 3, make([]float64, 6)), func(dst *matrix.Matrix) error { return matrix.HadamardInto(dst, a, b) }, hadamard},
+		{"apply", newMatrix(t, 2, 3, make([]float64, 6)), func(dst *matrix.Matrix) error { return matrix.ApplyInto(dst, a, square) }, applied},
+		{"copy", newMatrix(t, 2, 3, make([]float64, 6)), func(dst *matrix.Matrix) error { return matrix.CopyInto(dst, a) }, a},
+		{"add row vector", newMatrix(t, 2, 3, make([]float64, 6)), func(dst *matrix.Matrix) error { return matrix.AddRowVectorInto(dst, a, v) }, withRowVector},
+		{"sum columns", newMatrix(t, 1, 3, []float64{7, 7, 7}), func(dst *matrix.Matrix) error { return matrix.SumColumnsInto(dst, a) }, a.SumColumns()},
+		{"transpose", newMatrix(t, 3, 2, make([]float64, 6)), func(dst *matrix.Matrix) error { return matrix.TransposeInto(dst, a) }, a.T()},
+		{"mul", newMatrix(t, 2, 2, []float64{7, 7, 7, 7}), func(dst *matrix.Matrix) error { return matrix.MulInto(dst, a, b.T()) }, product},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.into(tc.dst); err != nil {
+				t.Fatalf("expected err to be nil, got %v", err)
+			}
+
+			ensureElements(t, tc.dst, tc.expected.FlattenedElements())
+		})
+	}
+}
+
+func TestIntoOperationsWithInvalidDestination(t *testing.T) {
+	a := newMatrix(t, 2, 2, []float64{1, 2, 3, 4})
+	wrongShape := newMatrix(t, 2, 3, make([]float64, 6))
+	aView, err := a.Slice(0, 2, 0, 2)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	aRow, err := a.Slice(0, 1, 0, a.Columns)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	rowVector := newMatrix(t, 1, 3, []float64{1, 2, 3})
+	testCases := []struct {
+		name string
+		into func() error
+	}{
+		{"nil destination", func() error { return matrix.AddInto(nil, a, a) }},
+		{"add to wrong shape", func() error { return matrix.AddInto(wrongShape, a, a) }},
+		{"copy to wrong shape", func() error { return matrix.CopyInto(wrongShape, a) }},
+		{"sum columns to wrong shape", func() error { return matrix.SumColumnsInto(a, a) }},
+		{"sum columns to the operand", func() error { return matrix.SumColumnsInto(rowVector, rowVector) }},
+		{"sum columns to a row of the operand", func() error { return matrix.SumColumnsInto(aRow, a) }},
+		{"mul to wrong shape", func() error { return matrix.MulInto(wrongShape, a, a) }},
+		{"mul to an operand", func() error { return matrix.MulInto(a, a, newMatrix(t, 2, 2, make([]float64, 4))) }},
+		{"mul to a view of an operand", func() error { return matrix.MulInto(aView, newMatrix(t, 2, 2, make([]float64, 4)), a) }},
+		{"transpose to itself", func() error { return matrix.TransposeInto(a, a) }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.into(); err == nil {
+				t.Errorf("expected err to be not nil")
+			}
+		})
+	}
+}
+
+func TestIntoOperationsDoNotAllocate(t *testing.T) {
+	a := newMatrix(t, 8, 8, make([]float64, 64))
+	b := newMatrix(t, 8, 8, make([]float64, 64))
+	dst := newMatrix(t, 8, 8, make([]float64, 64))
+
+	allocations := testing.AllocsPerRun(10, func() {
+		if err := matrix.MulInto(dst, a, b); err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		if err := matrix.AddInto(dst, dst, a); err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		if err := matrix.TransposeInto(b, dst); err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		dst.ScaleInPlace(0.5)
+	})
+	if allocations != 0 {
+		t.Errorf("expected no allocations, got %v", allocations)
+	}
+}
+
+func TestMulIntoOfLargeMatricesDoesNotAllocate(t *testing.T) {
+	for _, goroutines := range []int{1, 4} {
+		setParallelism(t, goroutines)
+		a := newMatrix(t, 130, 70, make([]float64, 9100))
+		b := newMatrix(t, 70, 70, make([]float64, 4900))
+		dst := newMatrix(t, 130, 70, make([]float64, 9100))
+
+		allocations := testing.AllocsPerRun(10, func() {
+			if err := matrix.MulInto(dst, a, b); err != nil {
+				t.Fatalf("expected err to be nil, got %v", err)
+			}
+		})
+		if allocations != 0 {
+			t.Errorf("expected no allocations on %d goroutines, got %v", goroutines, allocations)
+		}
+	}
+}
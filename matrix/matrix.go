@@ -6,36 +6,18 @@ import (
 	"strings"
 )
 
-const (
-	ElementWiseOperationSum            = "+"
-	ElementWiseOperationSubtraction    = "-"
-	ElementWiseOperationMultiplication = "*"
-	ElementWiseOperationDivision       = "/"
-)
-
-var (
-	scalarOperation = map[string]func(a, b float64) float64{
-		ElementWiseOperationSum: func(a, b float64) float64 {
-			return a + b
-		},
-		ElementWiseOperationSubtraction: func(a, b float64) float64 {
-			return a - b
-		},
-		ElementWiseOperationMultiplication: func(a, b float64) float64 {
-			return a * b
-		},
-		ElementWiseOperationDivision: func(a, b float64) float64 {
-			return a / b
-		},
-	}
-)
-
+// Matrix holds its elements row after row on a single slice. Row i
+// starts at i*stride, which is Columns unless the matrix is a view
+// of some columns of another one.
 type Matrix struct {
 	Rows    int
 	Columns int
-	data    [][]float64
+	stride  int
+	data    []float64
 }
 
+// New creates a matrix with a copy of flattenData, which holds the
+// elements row after row.
 func New(rows, columns int, flattenData []float64) (*Matrix, error) {
 	matrix, err := emptyMatrix(rows, columns)
 	if err != nil {
@@ -44,63 +26,140 @@ func New(rows, columns int, flattenData []float64) (*Matrix, error) {
 	if len(flattenData) != (rows * columns) {
 		return nil, fmt.Errorf("provided array cannot be arranged on a matrix of size %dx%d", rows, columns)
 	}
-	k := 0
-	for i := 0; i < rows; i++ {
-		for j := 0; j < columns; j++ {
-			matrix.data[i][j] = flattenData[k]
-			k++
-		}
-	}
+	copy(matrix.data, flattenData)
 	return matrix, nil
 }
 
-func emptyMatrix(rows, columns int) (*Matrix, error) {
-	if rows <= 0 {
-		return nil, fmt.Errorf("rows param must be > 0, received %v", rows)
-	}
-	if columns <= 0 {
-		return nil, fmt.Errorf("columns param must be > 0, received %v", rows)
+// NewFromSlice creates a matrix using data, which holds the elements
+// row after row, as its storage without copying it, so changes on
+// one of them are seen on the other.
+func NewFromSlice(rows, columns int, data []float64) (*Matrix, error) {
+	if err := checkShape(rows, columns); err != nil {
+		return nil, err
 	}
-	data := make([][]float64, rows)
-	for i := range data {
-		data[i] = make([]float64, columns)
+	if len(data) != (rows * columns) {
+		return nil, fmt.Errorf("provided array cannot be arranged on a matrix of size %dx%d", rows, columns)
 	}
 	return &Matrix{
 		Rows:    rows,
 		Columns: columns,
+		stride:  columns,
 		data:    data,
 	}, nil
 }
 
-// FlattenedElements returns all matrix elements on a slice
+func emptyMatrix(rows, columns int) (*Matrix, error) {
+	if err := checkShape(rows, columns); err != nil {
+		return nil, err
+	}
+	return &Matrix{
+		Rows:    rows,
+		Columns: columns,
+		stride:  columns,
+		data:    make([]float64, rows*columns),
+	}, nil
+}
+
+func checkShape(rows, columns int) error {
+	if rows <= 0 {
+		return fmt.Errorf("rows param must be > 0, received %v", rows)
+	}
+	if columns <= 0 {
+		return fmt.Errorf("columns param must be > 0, received %v", columns)
+	}
+	return nil
+}
+
+// Contiguous tells whether the rows follow each other on the storage
+// with nothing between them, which FlattenedElements then returns.
+func (m *Matrix) Contiguous() bool {
+	return m.stride == m.Columns
+}
+
+// row returns the elements of row i sharing the matrix storage.
+func (m *Matrix) row(i int) []float64 {
+	return m.data[i*m.stride : i*m.stride+m.Columns]
+}
+
+// FlattenedElements returns all matrix elements on a slice, row after
+// row. When the matrix is not a view of some columns of another one the
+// slice is its storage, taken in O(1), so it must not be changed: use
+// Copy first to get elements that can be.
 func (m *Matrix) FlattenedElements() []float64 {
-	flattenedElements := make([]float64, m.Rows*m.Columns)
-	index := 0
+	if m.Contiguous() {
+		return m.data[:m.Rows*m.Columns]
+	}
+	flattenedElements := make([]float64, 0, m.Rows*m.Columns)
 	for i := 0; i < m.Rows; i++ {
-		for j := 0; j < m.Columns; j++ {
-			flattenedElements[index] = m.data[i][j]
-			index++
-		}
+		flattenedElements = append(flattenedElements, m.row(i)...)
 	}
 	return flattenedElements
 }
 
+// Copy returns a matrix with the same elements which
+// doesn't share the storage of this one.
+func (m *Matrix) Copy() *Matrix {
+	copied := &Matrix{
+		Rows:    m.Rows,
+		Columns: m.Columns,
+		stride:  m.Columns,
+		data:    make([]float64, 0, m.Rows*m.Columns),
+	}
+	for i := 0; i < m.Rows; i++ {
+		copied.data = append(copied.data, m.row(i)...)
+	}
+	return copied
+}
+
+// Reshape returns the elements arranged on a matrix of the given shape,
+// row after row. It shares the storage of this matrix unless this one
+// is a view of some columns of another matrix.
+func (m *Matrix) Reshape(rows, columns int) (*Matrix, error) {
+	if err := checkShape(rows, columns); err != nil {
+		return nil, err
+	}
+	if rows*columns != m.Rows*m.Columns {
+		return nil, fmt.Errorf("matrix of shape (%dx%d) cannot be reshaped to (%dx%d)", m.Rows, m.Columns, rows, columns)
+	}
+	return NewFromSlice(rows, columns, m.FlattenedElements())
+}
+
+// Slice returns a view of rows [fromRow, toRow) and columns [fromColumn,
+// toColumn) sharing the storage of this matrix, so changes on one of
+// them are seen on the other.
+func (m *Matrix) Slice(fromRow, toRow, fromColumn, toColumn int) (*Matrix, error) {
+	if fromRow < 0 || toRow > m.Rows || fromRow >= toRow {
+		return nil, fmt.Errorf("rows [%d-%d) cannot be taken from a matrix with rows [%d-%d]", fromRow, toRow, 0, m.Rows-1)
+	}
+	if fromColumn < 0 || toColumn > m.Columns || fromColumn >= toColumn {
+		return nil, fmt.Errorf("columns [%d-%d) cannot be taken from a matrix with columns [%d-%d]", fromColumn, toColumn, 0, m.Columns-1)
+	}
+	begin := fromRow*m.stride + fromColumn
+	end := (toRow-1)*m.stride + toColumn
+	return &Matrix{
+		Rows:    toRow - fromRow,
+		Columns: toColumn - fromColumn,
+		stride:  m.stride,
+		data:    m.data[begin:end],
+	}, nil
+}
+
 // SumWith sums placehoder matrix with the given one
 // and returns the sum matrix if the sum can be done.
 func (m *Matrix) SumWith(a *Matrix) (*Matrix, error) {
-	return m.elementWiseOperation(a, ElementWiseOperationSum)
+	return m.ElementWise(a, ElementWiseOperationSum)
 }
 
 // Minus perfoms the subtraction from placeholder's elements
 // and retuns the subtraction matrixi if the operation can be done.
 func (m *Matrix) Minus(a *Matrix) (*Matrix, error) {
-	return m.elementWiseOperation(a, ElementWiseOperationSubtraction)
+	return m.ElementWise(a, ElementWiseOperationSubtraction)
 }
 
 // DotProductWith perfoms the dot product between two matrices
 // and retuns the result matrix if the operation can be done.
-//
-// Note: bellow implementation was designed for small matrices as it is O(n^3)
+// Large products are computed by tiles split across goroutines,
+// see SetParallelism.
 func (m *Matrix) DotProductWith(a *Matrix) (*Matrix, error) {
 	if a == nil {
 		return nil, fmt.Errorf("given matrix is nil")
@@ -113,21 +172,14 @@ func (m *Matrix) DotProductWith(a *Matrix) (*Matrix, error) {
 	if err != nil {
 		return nil, err
 	}
-	for i := range dotProductMatrix.data {
-		dotProductMatrix.data[i] = make([]float64, a.Columns)
-		for j := 0; j < a.Columns; j++ {
-			for k := 0; k < m.Columns; k++ {
-				dotProductMatrix.data[i][j] += m.data[i][k] * a.data[k][j]
-			}
-		}
-	}
+	multiply(dotProductMatrix, m, a)
 	return dotProductMatrix, nil
 }
 
 // HadamardProduct executes the Hadamard product and
 // return the result matrix if the operation is possible.
 func (m *Matrix) HadamardProductWith(a *Matrix) (*Matrix, error) {
-	return m.elementWiseOperation(a, ElementWiseOperationMultiplication)
+	return m.ElementWise(a, ElementWiseOperationMultiplication)
 }
 
 // SumOfAllElements sums all elements present and
@@ -135,64 +187,46 @@ func (m *Matrix) HadamardProductWith(a *Matrix) (*Matrix, error) {
 func (m *Matrix) SumOfAllElements() float64 {
 	sum := 0.0
 	for i := 0; i < m.Rows; i++ {
-		for j := 0; j < m.Columns; j++ {
-			sum += m.data[i][j]
+		for _, value := range m.row(i) {
+			sum += value
 		}
 	}
 	return sum
 }
 
-// T returns the matrix transpose
-func (m *Matrix) T() *Matrix {
-	transposedMatrix := &Matrix{
-		Rows:    m.Columns,
-		Columns: m.Rows,
-		data:    make([][]float64, m.Columns),
-	}
-	for i := range transposedMatrix.data {
-		transposedMatrix.data[i] = make([]float64, m.Rows)
-		for j := range transposedMatrix.data[i] {
-			transposedMatrix.data[i][j] = m.data[j][i]
-		}
-	}
-	return transposedMatrix
-}
-
-func (m *Matrix) elementWiseOperation(a *Matrix, operation string) (*Matrix, error) {
-	if a == nil {
-		return nil, fmt.Errorf("given matrix is nil")
-	}
-	elementWiseOperationCannotBeDone := !(m.Rows == a.Rows && m.Columns == a.Columns)
-	if elementWiseOperationCannotBeDone {
-		return nil, fmt.Errorf("matrices have different dimensions: this has (%d x %d) while given one has (%d x %d)", m.Rows, m.Columns, a.Rows, a.Columns)
-	}
+// SumWithRowVector sums the given row vector to every row of the placeholder
+// matrix and returns the sum matrix if the sum can be done.
+func (m *Matrix) SumWithRowVector(v *Matrix) (*Matrix, error) {
 	sumMatrix, err := emptyMatrix(m.Rows, m.Columns)
 	if err != nil {
 		return nil, err
 	}
-	for i := 0; i < m.Rows; i++ {
-		for j := 0; j < m.Columns; j++ {
-			mValue, err := m.GetAt(i, j)
-			if err != nil {
-				return nil, err
-			}
-			aValue, err := a.GetAt(i, j)
-			if err != nil {
-				return nil, err
-			}
-			if err := sumMatrix.SetAt(i, j, scalarOperation[operation](mValue, aValue)); err != nil {
-				return nil, err
-			}
-		}
+	if err := AddRowVectorInto(sumMatrix, m, v); err != nil {
+		return nil, err
 	}
 	return sumMatrix, nil
 }
 
+// SumColumns sums the elements of each column and
+// returns the sums as a row vector.
+func (m *Matrix) SumColumns() *Matrix {
+	sums, _ := emptyMatrix(1, m.Columns)
+	SumColumnsInto(sums, m)
+	return sums
+}
+
+// T returns the matrix transpose
+func (m *Matrix) T() *Matrix {
+	transposedMatrix, _ := emptyMatrix(m.Columns, m.Rows)
+	TransposeInto(transposedMatrix, m)
+	return transposedMatrix
+}
+
 func (m *Matrix) SetAt(rowIndex, columnIndex int, value float64) error {
 	if err := m.checkBounds(rowIndex, columnIndex); err != nil {
 		return err
 	}
-	m.data[rowIndex][columnIndex] = value
+	m.data[rowIndex*m.stride+columnIndex] = value
 	return nil
 }
 
@@ -200,7 +234,7 @@ func (m *Matrix) GetAt(rowIndex, columnIndex int) (float64, error) {
 	if err := m.checkBounds(rowIndex, columnIndex); err != nil {
 		return 0, err
 	}
-	return m.data[rowIndex][columnIndex], nil
+	return m.data[rowIndex*m.stride+columnIndex], nil
 }
 
 // ApplyElementWise takes a function as argument, applies it
@@ -213,16 +247,8 @@ func (m *Matrix) ApplyElementWise(f func(value float64) float64) (*Matrix, error
 	if err != nil {
 		return nil, err
 	}
-	for i := 0; i < newMatrix.Rows; i++ {
-		for j := 0; j < newMatrix.Columns; j++ {
-			originalMatrixElement, err := m.GetAt(i, j)
-			if err != nil {
-				return nil, err
-			}
-			if err := newMatrix.SetAt(i, j, f(originalMatrixElement)); err != nil {
-				return nil, err
-			}
-		}
+	if err := ApplyInto(newMatrix, m, f); err != nil {
+		return nil, err
 	}
 	return newMatrix, nil
 }
@@ -238,15 +264,15 @@ func (m *Matrix) Norm(norm int) (float64, error) {
 	case 1:
 		// L1 Norm (sum of absolute values of elements)
 		for i := 0; i < m.Rows; i++ {
-			for j := 0; j < m.Columns; j++ {
-				result += math.Abs(m.data[i][j])
+			for _, value := range m.row(i) {
+				result += math.Abs(value)
 			}
 		}
 	default:
 		// L2 Norm (square root of the sum of squared values)
 		for i := 0; i < m.Rows; i++ {
-			for j := 0; j < m.Columns; j++ {
-				result += m.data[i][j] * m.data[i][j]
+			for _, value := range m.row(i) {
+				result += value * value
 			}
 		}
 		result = math.Sqrt(result)
@@ -256,16 +282,16 @@ func (m *Matrix) Norm(norm int) (float64, error) {
 
 func (m *Matrix) checkBounds(rowIndex, columnIndex int) error {
 	if rowIndex >= m.Rows {
-		return fmt.Errorf("rowIndex out of bounds, matrix has rows [%d-%d]", 0, len(m.data)-1)
+		return fmt.Errorf("rowIndex out of bounds, matrix has rows [%d-%d]", 0, m.Rows-1)
 	}
 	if columnIndex >= m.Columns {
-		return fmt.Errorf("columnIndex out of bounds, matrix has columns [%d-%d]", 0, len(m.data[0])-1)
+		return fmt.Errorf("columnIndex out of bounds, matrix has columns [%d-%d]", 0, m.Columns-1)
 	}
 	if rowIndex < 0 {
 		return fmt.Errorf("row index must be >= 0, received %v", rowIndex)
 	}
 	if columnIndex < 0 {
-		return fmt.Errorf("column index must be >= 0, received %v", rowIndex)
+		return fmt.Errorf("column index must be >= 0, received %v", columnIndex)
 	}
 	return nil
 }
@@ -273,8 +299,8 @@ func (m *Matrix) checkBounds(rowIndex, columnIndex int) error {
 func (m *Matrix) ToString() string {
 	var sb strings.Builder
 	for i := 0; i < m.Rows; i++ {
-		for j := 0; j < m.Columns; j++ {
-			sb.WriteString(fmt.Sprintf("%v", m.data[i][j]))
+		for j, value := range m.row(i) {
+			sb.WriteString(fmt.Sprintf("%v", value))
 			if j < m.Columns-1 {
 				sb.WriteString(" ")
 			}
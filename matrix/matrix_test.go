@@ -580,3 +580,250 @@ func TestFrobeniusNormRatioForEqualMatrices(t *testing.T) {
 		t.Errorf("expected diff to be zero, got %v", err)
 	}
 }
+
+func TestSumWithRowVector(t *testing.T) {
+	m1, err := matrix.New(2, 3, []float64{1, 2, 3, 4, 5, 6})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	v, err := matrix.New(1, 3, []float64{10, 20, 30})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	result, err := m1.SumWithRowVector(v)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	expected := []float64{11, 22, 33, 14, 25, 36}
+	for i, value := range result.FlattenedElements() {
+		if value != expected[i] {
+			t.Errorf("expected element %d to be %v, got %v", i, expected[i], value)
+		}
+	}
+}
+
+func TestSumWithRowVectorWithInvalidShape(t *testing.T) {
+	m1, err := matrix.New(2, 3, []float64{1, 2, 3, 4, 5, 6})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	v, err := matrix.New(1, 2, []float64{10, 20})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	result, err := m1.SumWithRowVector(v)
+	if err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+	if result != nil {
+		t.Errorf("expected result to be nil")
+	}
+}
+
+func TestSumColumns(t *testing.T) {
+	m1, err := matrix.New(3, 2, []float64{1, 2, 3, 4, 5, 6})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	sums := m1.SumColumns()
+
+	if sums.Rows != 1 || sums.Columns != 2 {
+		t.Errorf("expected sums to be (1x2), got (%dx%d)", sums.Rows, sums.Columns)
+	}
+	expected := []float64{9, 12}
+	for i, value := range sums.FlattenedElements() {
+		if value != expected[i] {
+			t.Errorf("expected sum of column %d to be %v, got %v", i, expected[i], value)
+		}
+	}
+}
+
+func ensureElements(t *testing.T, m *matrix.Matrix, expected []float64) {
+	t.Helper()
+	elements := m.FlattenedElements()
+	if len(elements) != len(expected) {
+		t.Fatalf("expected %d elements, got %d", len(expected), len(elements))
+	}
+	for i, value := range elements {
+		if value != expected[i] {
+			t.Errorf("expected element %d to be %v, got %v", i, expected[i], value)
+		}
+	}
+}
+
+func TestNewCopiesGivenData(t *testing.T) {
+	data := []float64{1, 2, 3, 4}
+	m1, err := matrix.New(2, 2, data)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	data[0] = 10
+
+	ensureElements(t, m1, []float64{1, 2, 3, 4})
+}
+
+func TestNewFromSliceSharesGivenData(t *testing.T) {
+	data := []float64{1, 2, 3, 4}
+	m1, err := matrix.NewFromSlice(2, 2, data)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	data[0] = 10
+	if err := m1.SetAt(1, 1, 40); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	ensureElements(t, m1, []float64{10, 2, 3, 40})
+	if data[3] != 40 {
+		t.Errorf("expected data to be changed by the matrix, got %v", data)
+	}
+}
+
+func TestNewFromSliceWithInvalidShape(t *testing.T) {
+	if _, err := matrix.NewFromSlice(2, 2, []float64{1, 2, 3}); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+	if _, err := matrix.NewFromSlice(0, 2, nil); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+}
+
+func TestFlattenedElementsIsAView(t *testing.T) {
+	m1, err := matrix.New(2, 2, []float64{1, 2, 3, 4})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	flattened := m1.FlattenedElements()
+	if err := m1.SetAt(0, 1, 20); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	if flattened[1] != 20 {
+		t.Errorf("expected flattened elements to see the change, got %v", flattened)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	m1, err := matrix.New(2, 2, []float64{1, 2, 3, 4})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	copied := m1.Copy()
+	if err := m1.SetAt(0, 0, 10); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	ensureElements(t, copied, []float64{1, 2, 3, 4})
+}
+
+func TestReshape(t *testing.T) {
+	m1, err := matrix.New(2, 3, []float64{1, 2, 3, 4, 5, 6})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	reshaped, err := m1.Reshape(3, 2)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	if reshaped.Rows != 3 || reshaped.Columns != 2 {
+		t.Errorf("expected reshaped to be (3x2), got (%dx%d)", reshaped.Rows, reshaped.Columns)
+	}
+	if value, _ := reshaped.GetAt(2, 0); value != 5 {
+		t.Errorf("expected element (2, 0) to be 5, got %v", value)
+	}
+	if err := reshaped.SetAt(0, 0, 10); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if value, _ := m1.GetAt(0, 0); value != 10 {
+		t.Errorf("expected reshaped to share the storage, got %v", value)
+	}
+	if _, err := m1.Reshape(4, 2); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+}
+
+func TestSlice(t *testing.T) {
+	m1, err := matrix.New(3, 3, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	view, err := m1.Slice(1, 3, 1, 3)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	ensureElements(t, view, []float64{5, 6, 8, 9})
+	if err := view.SetAt(1, 0, 80); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if value, _ := m1.GetAt(2, 1); value != 80 {
+		t.Errorf("expected view to share the storage, got %v", value)
+	}
+	ensureElements(t, view.T(), []float64{5, 80, 6, 9})
+	ensureElements(t, view.Copy(), []float64{5, 6, 80, 9})
+	reshaped, err := view.Reshape(1, 4)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	ensureElements(t, reshaped, []float64{5, 6, 80, 9})
+
+	identity, err := matrix.New(2, 2, []float64{1, 0, 0, 1})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	product, err := view.DotProductWith(identity)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	ensureElements(t, product, []float64{5, 6, 80, 9})
+	sum, err := view.SumWith(view)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	ensureElements(t, sum, []float64{10, 12, 160, 18})
+	if view.ToString() != "5 6\n80 9" {
+		t.Errorf("expected view to be printed as its elements, got %q", view.ToString())
+	}
+}
+
+func TestSliceWithInvalidRanges(t *testing.T) {
+	m1, err := matrix.New(2, 2, []float64{1, 2, 3, 4})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	for _, r := range [][4]int{{-1, 1, 0, 1}, {0, 3, 0, 1}, {1, 1, 0, 1}, {0, 1, 1, 0}, {0, 1, 0, 3}} {
+		if _, err := m1.Slice(r[0], r[1], r[2], r[3]); err == nil {
+			t.Errorf("expected err to be not nil for %v", r)
+		}
+	}
+}
+
+func TestContiguous(t *testing.T) {
+	m := newMatrix(t, 3, 3, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9})
+	rows, err := m.Slice(1, 3, 0, 3)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	columns, err := m.Slice(0, 3, 1, 3)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	if !m.Contiguous() || !rows.Contiguous() {
+		t.Errorf("expected matrix and view of some rows to be contiguous")
+	}
+	if columns.Contiguous() {
+		t.Errorf("expected view of some columns to not be contiguous")
+	}
+}
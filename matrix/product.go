@@ -0,0 +1,170 @@
+package matrix
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// smallProductSize is the amount of multiplications below which
+	// products use the plain loop, as tiles and goroutines cost more
+	// than they save.
+	smallProductSize = 32 * 32 * 32
+
+	// tileSize is the side of the tiles of the operands worked on at
+	// once, so each one fits on the CPU cache while it is reused.
+	tileSize = 64
+)
+
+// parallelism is how many goroutines large products are split across.
+var parallelism atomic.Int32
+
+func init() {
+	parallelism.Store(int32(runtime.GOMAXPROCS(0)))
+}
+
+// SetParallelism sets how many goroutines large products are split
+// across, which is GOMAXPROCS by default. 1 computes them on the
+// calling goroutine.
+func SetParallelism(goroutines int) error {
+	if goroutines <= 0 {
+		return fmt.Errorf("goroutines must be > 0, received %d", goroutines)
+	}
+	parallelism.Store(int32(goroutines))
+	return nil
+}
+
+// Parallelism returns how many goroutines large products are split across.
+func Parallelism() int {
+	return int(parallelism.Load())
+}
+
+// multiply adds m.a to result, with the plain loop for small
+// products and by tiles split across goroutines otherwise.
+func multiply(result, m, a *Matrix) {
+	if m.Rows*m.Columns*a.Columns < smallProductSize {
+		multiplyRows(result, m, a, 0, m.Rows)
+		return
+	}
+	multiplyByTiles(result, m, a)
+}
+
+// multiplyRows adds to rows [from, to) of result the product of the same
+// rows of m by a, going through each row of a once per row of m.
+func multiplyRows(result, m, a *Matrix, from, to int) {
+	for i := from; i < to; i++ {
+		resultRow := result.row(i)
+		for k, mValue := range m.row(i) {
+			for j, aValue := range a.row(k) {
+				resultRow[j] += mValue * aValue
+			}
+		}
+	}
+}
+
+// multiplyByTiles adds m.a to result splitting the rows of result on
+// tiles shared by the goroutines. Each goroutine writes its own rows.
+func multiplyByTiles(result, m, a *Matrix) {
+	rowTiles := (m.Rows + tileSize - 1) / tileSize
+	goroutines := Parallelism()
+	if goroutines > rowTiles {
+		goroutines = rowTiles
+	}
+	if goroutines == 1 {
+		multiplyTiles(result, m, a, rowTiles, 0, 1)
+		return
+	}
+	multiplyInParallel(result, m, a, rowTiles, goroutines)
+}
+
+// tileShare is the part of a product given to a worker.
+type tileShare struct {
+	result, m, a          *Matrix
+	rowTiles, first, step int
+	done                  *sync.WaitGroup
+}
+
+var (
+	// tileShares feeds the workers products are split across. Workers
+	// are started when more are needed than ever before and then kept,
+	// so splitting a product neither starts goroutines nor allocates.
+	tileShares = make(chan tileShare)
+	workers    atomic.Int32
+	workersMu  sync.Mutex
+
+	// waitGroups keeps the wait groups of finished products to be reused.
+	waitGroups = sync.Pool{New: func() any { return new(sync.WaitGroup) }}
+)
+
+// multiplyInParallel gives tile g, g+goroutines, g+2*goroutines and so
+// on of the row tiles to goroutine g, the calling one being goroutine 0.
+func multiplyInParallel(result, m, a *Matrix, rowTiles, goroutines int) {
+	startWorkers(goroutines - 1)
+	done := waitGroups.Get().(*sync.WaitGroup)
+	done.Add(goroutines - 1)
+	for g := 1; g < goroutines; g++ {
+		tileShares <- tileShare{result: result, m: m, a: a, rowTiles: rowTiles, first: g, step: goroutines, done: done}
+	}
+	multiplyTiles(result, m, a, rowTiles, 0, goroutines)
+	done.Wait()
+	waitGroups.Put(done)
+}
+
+// startWorkers ensures at least n workers are running.
+func startWorkers(n int) {
+	if int(workers.Load()) >= n {
+		return
+	}
+	workersMu.Lock()
+	defer workersMu.Unlock()
+	for int(workers.Load()) < n {
+		go work()
+		workers.Add(1)
+	}
+}
+
+func work() {
+	for share := range tileShares {
+		multiplyTiles(share.result, share.m, share.a, share.rowTiles, share.first, share.step)
+		share.done.Done()
+	}
+}
+
+// multiplyTiles computes tile first, first+step, first+2*step
+// and so on of the row tiles of result.
+func multiplyTiles(result, m, a *Matrix, rowTiles, first, step int) {
+	for tile := first; tile < rowTiles; tile += step {
+		multiplyTile(result, m, a, tile*tileSize)
+	}
+}
+
+// multiplyTile computes the rows of result starting at fromRow, a tile
+// of m and a tile of a at a time, so both stay cached while reused.
+func multiplyTile(result, m, a *Matrix, fromRow int) {
+	toRow := minOf(fromRow+tileSize, m.Rows)
+	for fromK := 0; fromK < m.Columns; fromK += tileSize {
+		toK := minOf(fromK+tileSize, m.Columns)
+		for fromJ := 0; fromJ < a.Columns; fromJ += tileSize {
+			toJ := minOf(fromJ+tileSize, a.Columns)
+			for i := fromRow; i < toRow; i++ {
+				resultRow := result.row(i)[fromJ:toJ]
+				mRow := m.row(i)
+				for k := fromK; k < toK; k++ {
+					mValue := mRow[k]
+					for j, aValue := range a.row(k)[fromJ:toJ] {
+						resultRow[j] += mValue * aValue
+					}
+				}
+			}
+		}
+	}
+}
+
+func minOf(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,143 @@
+package matrix_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+func randomMatrix(t testing.TB, random *rand.Rand, rows, columns int) *matrix.Matrix {
+	data := make([]float64, rows*columns)
+	for i := range data {
+		data[i] = random.Float64()*2 - 1
+	}
+	m, err := matrix.New(rows, columns, data)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	return m
+}
+
+// referenceProduct computes the product element by element.
+func referenceProduct(m, a *matrix.Matrix) []float64 {
+	product := make([]float64, 0, m.Rows*a.Columns)
+	for i := 0; i < m.Rows; i++ {
+		for j := 0; j < a.Columns; j++ {
+			sum := 0.0
+			for k := 0; k < m.Columns; k++ {
+				mValue, _ := m.GetAt(i, k)
+				aValue, _ := a.GetAt(k, j)
+				sum += mValue * aValue
+			}
+			product = append(product, sum)
+		}
+	}
+	return product
+}
+
+func setParallelism(t *testing.T, goroutines int) {
+	previous := matrix.Parallelism()
+	if err := matrix.SetParallelism(goroutines); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	t.Cleanup(func() {
+		if err := matrix.SetParallelism(previous); err != nil {
+			t.Errorf("expected err to be nil, got %v", err)
+		}
+	})
+}
+
+func TestDotProductWithOfAnySize(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	shapes := [][3]int{{1, 1, 1}, {3, 3, 3}, {2, 5, 1}, {31, 33, 32}, {64, 64, 64}, {65, 130, 67}, {200, 3, 150}, {1, 300, 129}}
+	for _, goroutines := range []int{1, 4} {
+		setParallelism(t, goroutines)
+		for _, shape := range shapes {
+			m := randomMatrix(t, random, shape[0], shape[1])
+			a := randomMatrix(t, random, shape[1], shape[2])
+
+			product, err := m.DotProductWith(a)
+			if err != nil {
+				t.Fatalf("expected err to be nil, got %v", err)
+			}
+
+			if product.Rows != shape[0] || product.Columns != shape[2] {
+				t.Fatalf("expected product to be (%dx%d), got (%dx%d)", shape[0], shape[2], product.Rows, product.Columns)
+			}
+			for i, expected := range referenceProduct(m, a) {
+				if value := product.FlattenedElements()[i]; math.Abs(value-expected) > 1e-9 {
+					t.Errorf("%d goroutines, shape %v: expected element %d to be %v, got %v", goroutines, shape, i, expected, value)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestDotProductWithOfViews(t *testing.T) {
+	setParallelism(t, 3)
+	random := rand.New(rand.NewSource(2))
+	m, err := randomMatrix(t, random, 150, 160).Slice(5, 140, 10, 150)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	a, err := randomMatrix(t, random, 150, 100).Slice(3, 143, 1, 98)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	product, err := m.DotProductWith(a)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	for i, expected := range referenceProduct(m, a) {
+		if value := product.FlattenedElements()[i]; math.Abs(value-expected) > 1e-9 {
+			t.Fatalf("expected element %d to be %v, got %v", i, expected, value)
+		}
+	}
+}
+
+func TestSetParallelismWithInvalidValue(t *testing.T) {
+	if err := matrix.SetParallelism(0); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+}
+
+func BenchmarkDotProductWith(b *testing.B) {
+	random := rand.New(rand.NewSource(1))
+	for _, size := range []int{3, 16, 64, 128, 256, 512, 1024} {
+		m := randomMatrix(b, random, size, size)
+		a := randomMatrix(b, random, size, size)
+		b.Run(fmt.Sprintf("%dx%d", size, size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := m.DotProductWith(a); err != nil {
+					b.Fatalf("expected err to be nil, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDotProductWithOneGoroutine(b *testing.B) {
+	previous := matrix.Parallelism()
+	defer matrix.SetParallelism(previous)
+	if err := matrix.SetParallelism(1); err != nil {
+		b.Fatalf("expected err to be nil, got %v", err)
+	}
+	random := rand.New(rand.NewSource(1))
+	for _, size := range []int{64, 256, 1024} {
+		m := randomMatrix(b, random, size, size)
+		a := randomMatrix(b, random, size, size)
+		b.Run(fmt.Sprintf("%dx%d", size, size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := m.DotProductWith(a); err != nil {
+					b.Fatalf("expected err to be nil, got %v", err)
+				}
+			}
+		})
+	}
+}
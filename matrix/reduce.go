@@ -0,0 +1,177 @@
+package matrix
+
+import (
+	"fmt"
+	"math"
+)
+
+// Axis tells along which dimension a matrix is reduced.
+type Axis int
+
+const (
+	// AxisColumns reduces each column to a value, giving a row vector.
+	AxisColumns Axis = iota
+	// AxisRows reduces each row to a value, giving a column vector.
+	AxisRows
+)
+
+// SumRows sums the elements of each row and
+// returns the sums as a column vector.
+func (m *Matrix) SumRows() *Matrix {
+	sums, _ := emptyMatrix(m.Rows, 1)
+	SumRowsInto(sums, m)
+	return sums
+}
+
+// SumRowsInto writes the sum of the elements of each
+// row of a on dst, which must be a column vector.
+func SumRowsInto(dst, a *Matrix) error {
+	if a == nil {
+		return fmt.Errorf("given matrix is nil")
+	}
+	if err := checkDestination(dst, a.Rows, 1); err != nil {
+		return err
+	}
+	for i := 0; i < a.Rows; i++ {
+		sum := 0.0
+		for _, value := range a.row(i) {
+			sum += value
+		}
+		dst.data[i*dst.stride] = sum
+	}
+	return nil
+}
+
+// MeanAxis returns the mean of the elements along axis.
+func (m *Matrix) MeanAxis(axis Axis) (*Matrix, error) {
+	n, err := m.axisLength(axis)
+	if err != nil {
+		return nil, err
+	}
+	return m.sumAxis(axis).divideBy(n), nil
+}
+
+// VarianceAxis returns the population variance
+// of the elements along axis.
+func (m *Matrix) VarianceAxis(axis Axis) (*Matrix, error) {
+	n, err := m.axisLength(axis)
+	if err != nil {
+		return nil, err
+	}
+	means, err := m.MeanAxis(axis)
+	if err != nil {
+		return nil, err
+	}
+	deviations, err := m.BroadcastSub(means)
+	if err != nil {
+		return nil, err
+	}
+	if err := deviations.HadamardInPlace(deviations); err != nil {
+		return nil, err
+	}
+	return deviations.sumAxis(axis).divideBy(n), nil
+}
+
+// MaxAxis returns the biggest element along axis.
+func (m *Matrix) MaxAxis(axis Axis) (*Matrix, error) {
+	return m.reduceAxis(axis, math.Max)
+}
+
+// MinAxis returns the smallest element along axis.
+func (m *Matrix) MinAxis(axis Axis) (*Matrix, error) {
+	return m.reduceAxis(axis, math.Min)
+}
+
+// ArgMaxAxis returns the index of the biggest element along
+// axis, the first one when it shows up more than once.
+func (m *Matrix) ArgMaxAxis(axis Axis) ([]int, error) {
+	switch axis {
+	case AxisColumns:
+		indexes := make([]int, m.Columns)
+		for i := 1; i < m.Rows; i++ {
+			for j, value := range m.row(i) {
+				if value > m.data[indexes[j]*m.stride+j] {
+					indexes[j] = i
+				}
+			}
+		}
+		return indexes, nil
+	case AxisRows:
+		indexes := make([]int, m.Rows)
+		for i := 0; i < m.Rows; i++ {
+			row := m.row(i)
+			for j, value := range row {
+				if value > row[indexes[i]] {
+					indexes[i] = j
+				}
+			}
+		}
+		return indexes, nil
+	}
+	return nil, unsupportedAxis(axis)
+}
+
+func (m *Matrix) sumAxis(axis Axis) *Matrix {
+	if axis == AxisRows {
+		return m.SumRows()
+	}
+	return m.SumColumns()
+}
+
+// divideBy divides every element of a contiguous matrix by n.
+func (m *Matrix) divideBy(n int) *Matrix {
+	for i := range m.data {
+		m.data[i] /= float64(n)
+	}
+	return m
+}
+
+// reduceAxis folds the elements along axis with f,
+// starting from the first one of each column or row.
+func (m *Matrix) reduceAxis(axis Axis, f func(accumulated, value float64) float64) (*Matrix, error) {
+	switch axis {
+	case AxisColumns:
+		result, err := emptyMatrix(1, m.Columns)
+		if err != nil {
+			return nil, err
+		}
+		accumulated := result.row(0)
+		copy(accumulated, m.row(0))
+		for i := 1; i < m.Rows; i++ {
+			for j, value := range m.row(i) {
+				accumulated[j] = f(accumulated[j], value)
+			}
+		}
+		return result, nil
+	case AxisRows:
+		result, err := emptyMatrix(m.Rows, 1)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < m.Rows; i++ {
+			row := m.row(i)
+			accumulated := row[0]
+			for _, value := range row[1:] {
+				accumulated = f(accumulated, value)
+			}
+			result.data[i] = accumulated
+		}
+		return result, nil
+	}
+	return nil, unsupportedAxis(axis)
+}
+
+// axisLength returns how many elements are reduced to each value along axis.
+func (m *Matrix) axisLength(axis Axis) (int, error) {
+	switch axis {
+	case AxisColumns:
+		return m.Rows, nil
+	case AxisRows:
+		return m.Columns, nil
+	}
+	return 0, unsupportedAxis(axis)
+}
+
+func unsupportedAxis(axis Axis) error {
+	return fmt.Errorf("unsupported axis %d, only AxisColumns and AxisRows are supported", axis)
+}
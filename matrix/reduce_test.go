@@ -0,0 +1,134 @@
+package matrix_test
+
+import (
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+func TestSumRows(t *testing.T) {
+	m := newMatrix(t, 2, 3, []float64{1, 2, 3, 4, 5, 6})
+
+	sums := m.SumRows()
+
+	if sums.Rows != 2 || sums.Columns != 1 {
+		t.Fatalf("expected sums to be (2x1), got (%dx%d)", sums.Rows, sums.Columns)
+	}
+	ensureElements(t, sums, []float64{6, 15})
+}
+
+func TestAxisReductions(t *testing.T) {
+	m := newMatrix(t, 3, 2, []float64{
+		1, 8,
+		4, -2,
+		7, 8,
+	})
+	testCases := []struct {
+		name    string
+		reduce  func(axis matrix.Axis) (*matrix.Matrix, error)
+		columns []float64
+		rows    []float64
+	}{
+		{"mean", m.MeanAxis, []float64{4, 14.0 / 3}, []float64{4.5, 1, 7.5}},
+		{"max", m.MaxAxis, []float64{7, 8}, []float64{8, 4, 8}},
+		{"min", m.MinAxis, []float64{1, -2}, []float64{1, -2, 7}},
+		{"variance", m.VarianceAxis, []float64{6, 200.0 / 9}, []float64{12.25, 9, 0.25}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			columns, err := tc.reduce(matrix.AxisColumns)
+			if err != nil {
+				t.Fatalf("expected err to be nil, got %v", err)
+			}
+			rows, err := tc.reduce(matrix.AxisRows)
+			if err != nil {
+				t.Fatalf("expected err to be nil, got %v", err)
+			}
+
+			if columns.Rows != 1 || columns.Columns != 2 {
+				t.Errorf("expected reduction of columns to be (1x2), got (%dx%d)", columns.Rows, columns.Columns)
+			}
+			ensureClose(t, columns, tc.columns)
+			if rows.Rows != 3 || rows.Columns != 1 {
+				t.Errorf("expected reduction of rows to be (3x1), got (%dx%d)", rows.Rows, rows.Columns)
+			}
+			ensureClose(t, rows, tc.rows)
+			if _, err := tc.reduce(matrix.Axis(2)); err == nil {
+				t.Errorf("expected err to be not nil")
+			}
+		})
+	}
+}
+
+func TestAxisReductionsOfViews(t *testing.T) {
+	m := newMatrix(t, 3, 3, []float64{
+		1, 2, 3,
+		4, 5, 6,
+		7, 8, 9,
+	})
+	view, err := m.Slice(1, 3, 1, 3)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	means, err := view.MeanAxis(matrix.AxisColumns)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	maxValues, err := view.MaxAxis(matrix.AxisRows)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	ensureElements(t, means, []float64{6.5, 7.5})
+	ensureElements(t, maxValues, []float64{6, 9})
+	ensureElements(t, view.SumRows(), []float64{11, 17})
+}
+
+func TestArgMaxAxis(t *testing.T) {
+	m := newMatrix(t, 3, 3, []float64{
+		1, 9, 9,
+		5, 2, 9,
+		5, 3, -1,
+	})
+
+	columns, err := m.ArgMaxAxis(matrix.AxisColumns)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	rows, err := m.ArgMaxAxis(matrix.AxisRows)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	ensureIndexes(t, columns, []int{1, 0, 0})
+	ensureIndexes(t, rows, []int{1, 2, 0})
+	if _, err := m.ArgMaxAxis(matrix.Axis(-1)); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+}
+
+func ensureClose(t *testing.T, m *matrix.Matrix, expected []float64) {
+	t.Helper()
+	elements := m.FlattenedElements()
+	if len(elements) != len(expected) {
+		t.Fatalf("expected %d elements, got %d", len(expected), len(elements))
+	}
+	for i, value := range elements {
+		if diff := value - expected[i]; diff > 1e-12 || diff < -1e-12 {
+			t.Errorf("expected element %d to be %v, got %v", i, expected[i], value)
+		}
+	}
+}
+
+func ensureIndexes(t *testing.T, indexes, expected []int) {
+	t.Helper()
+	if len(indexes) != len(expected) {
+		t.Fatalf("expected %d indexes, got %d", len(expected), len(indexes))
+	}
+	for i, index := range indexes {
+		if index != expected[i] {
+			t.Errorf("expected index %d to be %d, got %d", i, expected[i], index)
+		}
+	}
+}
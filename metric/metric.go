@@ -0,0 +1,99 @@
+package metric
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+// Metric measures the quality of predictions, to be reported along the
+// loss. Each row of expected and predicted refers to one sample. Every
+// loss.Loss is also a Metric.
+type Metric interface {
+	// Name identifies the metric.
+	Name() string
+	// Value returns the metric computed over all samples.
+	Value(expected, predicted *matrix.Matrix) (float64, error)
+}
+
+// Accuracy is the fraction of samples whose class is predicted right.
+// With one column the class is 1 when the value is >= 0.5 and 0 otherwise,
+// with more columns it is the column of the biggest value.
+type Accuracy struct{}
+
+func NewAccuracy() *Accuracy {
+	return &Accuracy{}
+}
+
+func (m *Accuracy) Name() string {
+	return "accuracy"
+}
+
+func (m *Accuracy) Value(expected, predicted *matrix.Matrix) (float64, error) {
+	if err := checkShapes(expected, predicted); err != nil {
+		return 0, err
+	}
+	columns := expected.Columns
+	e, p := expected.FlattenedElements(), predicted.FlattenedElements()
+	right := 0
+	for i := 0; i < expected.Rows; i++ {
+		if classOf(e[i*columns:(i+1)*columns]) == classOf(p[i*columns:(i+1)*columns]) {
+			right++
+		}
+	}
+	return float64(right) / float64(expected.Rows), nil
+}
+
+func classOf(values []float64) int {
+	if len(values) == 1 {
+		if values[0] >= 0.5 {
+			return 1
+		}
+		return 0
+	}
+	class := 0
+	for i, value := range values {
+		if value > values[class] {
+			class = i
+		}
+	}
+	return class
+}
+
+// RMSE is the square root of the mean squared error, which is in
+// the same unit as the predicted values.
+type RMSE struct{}
+
+func NewRMSE() *RMSE {
+	return &RMSE{}
+}
+
+func (m *RMSE) Name() string {
+	return "rmse"
+}
+
+func (m *RMSE) Value(expected, predicted *matrix.Matrix) (float64, error) {
+	if err := checkShapes(expected, predicted); err != nil {
+		return 0, err
+	}
+	e, p := expected.FlattenedElements(), predicted.FlattenedElements()
+	sum := 0.0
+	for i := range e {
+		sum += (p[i] - e[i]) * (p[i] - e[i])
+	}
+	return math.Sqrt(sum / float64(len(e))), nil
+}
+
+func checkShapes(expected, predicted *matrix.Matrix) error {
+	if expected == nil || predicted == nil {
+		return fmt.Errorf("expected and predicted cannot be nil")
+	}
+	if expected.Rows != predicted.Rows || expected.Columns != predicted.Columns {
+		return fmt.Errorf("expected and predicted must have the same shape, got %dx%d and %dx%d", expected.Rows, expected.Columns, predicted.Rows, predicted.Columns)
+	}
+	if expected.Rows == 0 {
+		return fmt.Errorf("at least one sample must be given")
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package metric_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/loss"
+	"github.com/buarki/supervised-machine-learning/matrix"
+	"github.com/buarki/supervised-machine-learning/metric"
+)
+
+const (
+	acceptedError = 1e-9
+)
+
+func newMatrix(t *testing.T, rows, columns int, data []float64) *matrix.Matrix {
+	m, err := matrix.New(rows, columns, data)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	return m
+}
+
+func TestAccuracy(t *testing.T) {
+	testCases := []struct {
+		expected  *matrix.Matrix
+		predicted *matrix.Matrix
+		accuracy  float64
+	}{
+		{
+			expected:  newMatrix(t, 4, 1, []float64{1, 0, 1, 0}),
+			predicted: newMatrix(t, 4, 1, []float64{0.7, 0.2, 0.4, 0.5}),
+			accuracy:  0.5,
+		},
+		{
+			expected:  newMatrix(t, 3, 3, []float64{1, 0, 0, 0, 1, 0, 0, 0, 1}),
+			predicted: newMatrix(t, 3, 3, []float64{0.6, 0.3, 0.1, 0.2, 0.7, 0.1, 0.5, 0.1, 0.4}),
+			accuracy:  2.0 / 3.0,
+		},
+	}
+	for _, testCase := range testCases {
+		value, err := metric.NewAccuracy().Value(testCase.expected, testCase.predicted)
+		if err != nil {
+			t.Errorf("expected err to be nil, got %v", err)
+		}
+		if math.Abs(value-testCase.accuracy) > acceptedError {
+			t.Errorf("expected accuracy to be %v, got %v", testCase.accuracy, value)
+		}
+	}
+}
+
+func TestRMSE(t *testing.T) {
+	expected := newMatrix(t, 2, 1, []float64{1, 0})
+	predicted := newMatrix(t, 2, 1, []float64{0, 2})
+
+	value, err := metric.NewRMSE().Value(expected, predicted)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if expectedValue := math.Sqrt(5.0 / 2.0); math.Abs(value-expectedValue) > acceptedError {
+		t.Errorf("expected value to be %v, got %v", expectedValue, value)
+	}
+}
+
+func TestLossIsMetric(t *testing.T) {
+	var m metric.Metric = loss.NewMAE()
+	if m.Name() != "mae" {
+		t.Errorf("expected metric to be mae, got %s", m.Name())
+	}
+}
+
+func TestMetricsWithDifferentShapes(t *testing.T) {
+	expected := newMatrix(t, 2, 1, []float64{1, 0})
+	predicted := newMatrix(t, 1, 2, []float64{1, 0})
+	for _, m := range []metric.Metric{metric.NewAccuracy(), metric.NewRMSE()} {
+		if _, err := m.Value(expected, predicted); err == nil {
+			t.Errorf("%s: expected err to be not nil", m.Name())
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package neuralnet
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+// batchConfig tells how samples are grouped into the batches
+// learned on each epoch. A zero size keeps the given batches.
+type batchConfig struct {
+	size      int
+	dropLast  bool
+	noShuffle bool
+}
+
+// samples is the flat list of rows of every training data,
+// so they can be grouped into batches of any size.
+type samples struct {
+	x, y            []float64
+	inputs, outputs int
+	count           int
+
+	// order and the batches are kept to be filled again on every epoch
+	order   []int
+	buffers []batchBuffer
+	built   []TrainingData
+}
+
+// batchBuffer is a batch along with the elements of its matrices.
+type batchBuffer struct {
+	data TrainingData
+	x, y []float64
+}
+
+func newSamples(trainingData []TrainingData) (*samples, error) {
+	s := &samples{}
+	for i, data := range trainingData {
+		if data.X == nil || data.Y == nil {
+			return nil, fmt.Errorf("training data %d must have X and Y", i)
+		}
+		if data.X.Rows != data.Y.Rows {
+			return nil, fmt.Errorf("training data %d has %d rows on X and %d on Y", i, data.X.Rows, data.Y.Rows)
+		}
+		if i == 0 {
+			s.inputs, s.outputs = data.X.Columns, data.Y.Columns
+		}
+		if data.X.Columns != s.inputs || data.Y.Columns != s.outputs {
+			return nil, fmt.Errorf("training data %d has %d inputs and %d outputs, expected %d and %d", i, data.X.Columns, data.Y.Columns, s.inputs, s.outputs)
+		}
+		s.x = append(s.x, data.X.FlattenedElements()...)
+		s.y = append(s.y, data.Y.FlattenedElements()...)
+		s.count += data.X.Rows
+	}
+	return s, nil
+}
+
+// batches groups the samples, taken in the order of s.order, into
+// batches of size rows. The last one has the remaining rows unless
+// dropLast. The batches are built on the first call and filled again
+// on the next ones, so they are only valid until then.
+func (s *samples) batches(size int, dropLast bool) ([]TrainingData, error) {
+	if s.buffers == nil {
+		if err := s.buildBatches(size, dropLast); err != nil {
+			return nil, err
+		}
+	}
+	for k, batch := range s.buffers {
+		start := k * size
+		for row, i := range s.order[start : start+batch.data.X.Rows] {
+			copy(batch.x[row*s.inputs:(row+1)*s.inputs], s.x[i*s.inputs:(i+1)*s.inputs])
+			copy(batch.y[row*s.outputs:(row+1)*s.outputs], s.y[i*s.outputs:(i+1)*s.outputs])
+		}
+	}
+	return s.built, nil
+}
+
+func (s *samples) buildBatches(size int, dropLast bool) error {
+	s.buffers = []batchBuffer{}
+	s.built = []TrainingData{}
+	for start := 0; start < s.count; start += size {
+		rows := size
+		if start+rows > s.count {
+			if dropLast {
+				break
+			}
+			rows = s.count - start
+		}
+		batch := batchBuffer{x: make([]float64, rows*s.inputs), y: make([]float64, rows*s.outputs)}
+		X, err := matrix.NewFromSlice(rows, s.inputs, batch.x)
+		if err != nil {
+			return fmt.Errorf("failed to build batch X, got %v", err)
+		}
+		Y, err := matrix.NewFromSlice(rows, s.outputs, batch.y)
+		if err != nil {
+			return fmt.Errorf("failed to build batch Y, got %v", err)
+		}
+		batch.data = TrainingData{X: X, Y: Y}
+		s.buffers = append(s.buffers, batch)
+		s.built = append(s.built, batch.data)
+	}
+	return nil
+}
+
+// batchesFor returns the batches to learn on the next epoch. Without a
+// batch size they are the given training data, otherwise the samples
+// are shuffled with the network source and grouped again.
+func (nn *NeuralNet) batchesFor(trainingData []TrainingData, s *samples, config batchConfig) ([]TrainingData, error) {
+	if config.size == 0 {
+		return trainingData, nil
+	}
+	if s.order == nil {
+		s.order = make([]int, s.count)
+	}
+	if config.noShuffle {
+		for i := range s.order {
+			s.order[i] = i
+		}
+	} else {
+		permute(nn.random, s.order)
+	}
+	batches, err := s.batches(config.size, config.dropLast)
+	if err != nil {
+		return nil, err
+	}
+	if len(batches) == 0 {
+		return nil, fmt.Errorf("no batch of %d samples can be built from %d samples", config.size, s.count)
+	}
+	return batches, nil
+}
+
+// permute writes on order a random permutation of its indexes,
+// the same rand.Perm would return for the same random state.
+func permute(random *rand.Rand, order []int) {
+	for i := range order {
+		j := random.Intn(i + 1)
+		order[i] = order[j]
+		order[j] = i
+	}
+}
@@ -0,0 +1,114 @@
+package neuralnet
+
+import (
+	"errors"
+	"log"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+// ErrStopTraining can be returned by a callback to end the train without
+// an error. Best params are still restored when early stopping is used.
+var ErrStopTraining = errors.New("training stopped by callback")
+
+// TrainEvent describes the point of the train a callback is called at.
+// Fields not known at that point keep their zero value. The same event
+// is given to every call, so it must be copied to be kept.
+type TrainEvent struct {
+	Model        *NeuralNet
+	Epoch        int     // Index of the current epoch, starting at 0
+	Epochs       int     // Epochs the train runs for, unless stopped
+	Batch        int     // Index of the batch in the epoch, only for OnBatchEnd
+	Batches      int     // Batches of the epoch, only for OnBatchEnd
+	Step         int     // Batches learned since the train started
+	LearningRate float64 // Learning rate of the last batch
+
+	// Loss is the one of the batch on OnBatchEnd and the average
+	// of the batches of the epoch, weighted by their amount of
+	// samples, on OnEpochEnd.
+	Loss float64
+
+	// TrainMetrics are the metrics given by WithMetrics measured
+	// on the training batches of the epoch, only for OnEpochEnd.
+	TrainMetrics map[string]float64
+
+	// Validation is only set on OnEpochEnd of the epochs
+	// the network is validated, see WithValidation.
+	Validation *Validation
+
+	// Gradients are dE/dParam of each param, in the order of
+	// NeuralNet.Params, only for OnBatchEnd. They are reused
+	// by the next batch, so they must be copied to be kept.
+	Gradients []*matrix.Matrix
+}
+
+// Callback is notified along the train. Returning ErrStopTraining from
+// any hook stops it, while any other error makes Train fail with it.
+type Callback interface {
+	OnTrainBegin(event *TrainEvent) error
+	OnEpochBegin(event *TrainEvent) error
+	OnBatchEnd(event *TrainEvent) error
+	OnEpochEnd(event *TrainEvent) error
+	OnTrainEnd(event *TrainEvent) error
+}
+
+// BaseCallback implements every hook doing nothing, so callbacks
+// can embed it and only implement the hooks they care about.
+type BaseCallback struct{}
+
+func (BaseCallback) OnTrainBegin(event *TrainEvent) error { return nil }
+func (BaseCallback) OnEpochBegin(event *TrainEvent) error { return nil }
+func (BaseCallback) OnBatchEnd(event *TrainEvent) error   { return nil }
+func (BaseCallback) OnEpochEnd(event *TrainEvent) error   { return nil }
+func (BaseCallback) OnTrainEnd(event *TrainEvent) error   { return nil }
+
+type callbacks []Callback
+
+// notify calls hook on every callback in order, stopping at
+// the first one that returns an error.
+func (c callbacks) notify(hook func(Callback, *TrainEvent) error, event *TrainEvent) error {
+	for _, callback := range c {
+		if err := hook(callback, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProgressLogger is a callback logging the start and end of every
+// epoch, along with its validation if any, and the end of every
+// batch when created to do so.
+type ProgressLogger struct {
+	BaseCallback
+	logBatches bool
+}
+
+// NewProgressLogger returns a ProgressLogger, which also logs
+// every batch when logBatches is true.
+func NewProgressLogger(logBatches bool) *ProgressLogger {
+	return &ProgressLogger{logBatches: logBatches}
+}
+
+func (p *ProgressLogger) OnEpochBegin(event *TrainEvent) error {
+	log.Printf("starting epoch %d/%d\n", event.Epoch+1, event.Epochs)
+	return nil
+}
+
+func (p *ProgressLogger) OnBatchEnd(event *TrainEvent) error {
+	if p.logBatches {
+		log.Printf("learned using data %d/%d, got error %.7f\n", event.Batch+1, event.Batches, event.Loss)
+	}
+	return nil
+}
+
+func (p *ProgressLogger) OnEpochEnd(event *TrainEvent) error {
+	if event.TrainMetrics != nil {
+		log.Printf("finished epoch %d, learning rate %v, got error %.7f and metrics %v\n", event.Epoch+1, event.LearningRate, event.Loss, event.TrainMetrics)
+	} else {
+		log.Printf("finished epoch %d, learning rate %v, got error %.7f\n", event.Epoch+1, event.LearningRate, event.Loss)
+	}
+	if event.Validation != nil {
+		log.Printf("validated epoch %d, got loss %.7f and metrics %v\n", event.Epoch+1, event.Validation.Loss, event.Validation.Metrics)
+	}
+	return nil
+}
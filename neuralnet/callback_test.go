@@ -0,0 +1,216 @@
+package neuralnet_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/metric"
+	"github.com/buarki/supervised-machine-learning/neuralnet"
+)
+
+// recordingCallback records each hook called along with the epoch and
+// batch, and returns stopWith once stopAfter hooks were called.
+type recordingCallback struct {
+	calls     []string
+	events    []neuralnet.TrainEvent
+	stopAfter int
+	stopWith  error
+}
+
+func (c *recordingCallback) record(hook string, event *neuralnet.TrainEvent) error {
+	c.calls = append(c.calls, fmt.Sprintf("%s %d %d", hook, event.Epoch, event.Batch))
+	c.events = append(c.events, *event)
+	if c.stopAfter > 0 && len(c.calls) >= c.stopAfter {
+		return c.stopWith
+	}
+	return nil
+}
+
+func (c *recordingCallback) OnTrainBegin(event *neuralnet.TrainEvent) error {
+	return c.record("train begin", event)
+}
+
+func (c *recordingCallback) OnEpochBegin(event *neuralnet.TrainEvent) error {
+	return c.record("epoch begin", event)
+}
+
+func (c *recordingCallback) OnBatchEnd(event *neuralnet.TrainEvent) error {
+	return c.record("batch end", event)
+}
+
+func (c *recordingCallback) OnEpochEnd(event *neuralnet.TrainEvent) error {
+	return c.record("epoch end", event)
+}
+
+func (c *recordingCallback) OnTrainEnd(event *neuralnet.TrainEvent) error {
+	return c.record("train end", event)
+}
+
+func TestTrainNotifiesCallbacks(t *testing.T) {
+	nn := newConstantNeuralNet(t)
+	training, validation := divergingData(t)
+	callback := &recordingCallback{}
+
+	_, err := neuralnet.Train(nn, 2, append(training, training...),
+		neuralnet.WithCallbacks(callback),
+		neuralnet.WithValidation(validation, 2),
+		neuralnet.WithMetrics(metric.NewRMSE()),
+	)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	expectedCalls := []string{
+		"train begin 0 0",
+		"epoch begin 0 0",
+		"batch end 0 0",
+		"batch end 0 1",
+		"epoch end 0 0",
+		"epoch begin 1 0",
+		"batch end 1 0",
+		"batch end 1 1",
+		"epoch end 1 0",
+		"train end 2 0",
+	}
+	if !reflect.DeepEqual(callback.calls, expectedCalls) {
+		t.Fatalf("expected calls to be %v, got %v", expectedCalls, callback.calls)
+	}
+	for i, event := range callback.events {
+		if event.Model != nn {
+			t.Errorf("expected event %d to have the trained model", i)
+		}
+		if event.Epochs != 2 {
+			t.Errorf("expected event %d to have 2 epochs, got %d", i, event.Epochs)
+		}
+	}
+	batchEnd := callback.events[3]
+	if batchEnd.Step != 2 {
+		t.Errorf("expected step to be 2, got %d", batchEnd.Step)
+	}
+	if len(batchEnd.Gradients) != len(nn.Params()) {
+		t.Errorf("expected %d gradients, got %d", len(nn.Params()), len(batchEnd.Gradients))
+	}
+	if batchEnd.Loss <= 0 || batchEnd.LearningRate != nn.LearningRate() {
+		t.Errorf("expected batch loss and learning rate to be set, got %v and %v", batchEnd.Loss, batchEnd.LearningRate)
+	}
+	if callback.events[4].Validation != nil {
+		t.Errorf("expected first epoch not to be validated")
+	}
+	lastEpochEnd := callback.events[8]
+	if lastEpochEnd.Validation == nil {
+		t.Fatalf("expected last epoch to be validated")
+	}
+	if _, ok := lastEpochEnd.Validation.Metrics["rmse"]; !ok {
+		t.Errorf("expected validation to have rmse, got %v", lastEpochEnd.Validation.Metrics)
+	}
+}
+
+func TestCallbackStopsTraining(t *testing.T) {
+	nn := newConstantNeuralNet(t)
+	training, _ := divergingData(t)
+	callback := &recordingCallback{stopAfter: 4, stopWith: neuralnet.ErrStopTraining}
+
+	if _, err := neuralnet.Train(nn, 10, append(training, training...), neuralnet.WithCallbacks(callback)); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	expectedCalls := []string{
+		"train begin 0 0",
+		"epoch begin 0 0",
+		"batch end 0 0",
+		"batch end 0 1",
+		"train end 0 0",
+	}
+	if !reflect.DeepEqual(callback.calls, expectedCalls) {
+		t.Errorf("expected calls to be %v, got %v", expectedCalls, callback.calls)
+	}
+}
+
+func TestCallbackFailsTraining(t *testing.T) {
+	nn := newConstantNeuralNet(t)
+	training, _ := divergingData(t)
+	callback := &recordingCallback{stopAfter: 2, stopWith: errors.New("dashboard is down")}
+
+	if _, err := neuralnet.Train(nn, 10, training, neuralnet.WithCallbacks(callback)); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+	if len(callback.calls) != 2 {
+		t.Errorf("expected train to fail on the second hook, got %d hooks called", len(callback.calls))
+	}
+}
+
+// epochCounter only implements OnEpochEnd.
+type epochCounter struct {
+	neuralnet.BaseCallback
+	epochs int
+}
+
+func (c *epochCounter) OnEpochEnd(event *neuralnet.TrainEvent) error {
+	c.epochs++
+	return nil
+}
+
+func TestCallbackEmbeddingBaseCallback(t *testing.T) {
+	nn := newConstantNeuralNet(t)
+	training, _ := divergingData(t)
+	counter := &epochCounter{}
+
+	if _, err := neuralnet.Train(nn, 3, training, neuralnet.WithCallbacks(counter)); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if counter.epochs != 3 {
+		t.Errorf("expected 3 epochs, got %d", counter.epochs)
+	}
+}
+
+func TestTrainWithNilCallback(t *testing.T) {
+	training, _ := divergingData(t)
+	if _, err := neuralnet.Train(newConstantNeuralNet(t), 1, training, neuralnet.WithCallbacks(nil)); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+}
+
+func TestProgressLogger(t *testing.T) {
+	training, validation := divergingData(t)
+	testCases := []struct {
+		name       string
+		logBatches bool
+		lines      int
+	}{
+		{"epochs", false, 5},
+		{"batches", true, 9},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var output bytes.Buffer
+			defer log.SetOutput(log.Writer())
+			log.SetOutput(&output)
+
+			_, err := neuralnet.Train(newConstantNeuralNet(t), 2, append(training, training...),
+				neuralnet.WithCallbacks(neuralnet.NewProgressLogger(tc.logBatches)),
+				neuralnet.WithValidation(validation, 2),
+			)
+			if err != nil {
+				t.Fatalf("expected err to be nil, got %v", err)
+			}
+
+			logged := output.String()
+			if lines := strings.Count(logged, "\n"); lines != tc.lines {
+				t.Errorf("expected %d lines to be logged, got %d: %s", tc.lines, lines, logged)
+			}
+			for _, expected := range []string{"starting epoch 2/2", "finished epoch 2", "validated epoch 2"} {
+				if !strings.Contains(logged, expected) {
+					t.Errorf("expected %q to be logged, got %s", expected, logged)
+				}
+			}
+			if tc.logBatches != strings.Contains(logged, "learned using data 2/2") {
+				t.Errorf("expected batches to be logged only when asked, got %s", logged)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package neuralnet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+	"github.com/buarki/supervised-machine-learning/optimizer"
+	"github.com/buarki/supervised-machine-learning/schedule"
+)
+
+// checkpointFormatVersion is increased whenever checkpointFile changes
+// in a way older versions can't read.
+const checkpointFormatVersion = 1
+
+// checkpointFile holds everything needed to continue a training
+// exactly as if it had not been interrupted.
+type checkpointFile struct {
+	Version     int                `json:"version"`
+	Model       *modelFile         `json:"model"`
+	Epoch       int                `json:"epoch"`
+	Step        int                `json:"step"`
+	RandomState *uint64            `json:"randomState,omitempty"` // Only set for sources built by NewSource
+	BestLoss    *float64           `json:"bestLoss,omitempty"`    // Not set before the first monitored loss
+	BestEpoch   int                `json:"bestEpoch,omitempty"`
+	BestParams  []paramFile        `json:"bestParams,omitempty"` // Only set for early stopping
+	Optimizer   *optimizer.State   `json:"optimizer,omitempty"`
+	Schedule    map[string]float64 `json:"schedule,omitempty"`
+}
+
+// Resume continues the training saved on the checkpoint at path until epochs
+// epochs are finished. The network must be built as the checkpointed one, with
+// the same layers, the same kind of optimizer and a source built by NewSource,
+// and data and options must be the ones given to Train, so the result is exactly
+// the one of an uninterrupted run. Checkpoints keep being written if the options
+// ask for them. The history only has the epochs run after the checkpoint.
+func Resume(nn *NeuralNet, path string, epochs int, trainingData []TrainingData, options ...TrainOption) (*History, error) {
+	return ResumeContext(context.Background(), nn, path, epochs, trainingData, options...)
+}
+
+// ResumeContext is Resume stopping once ctx is done, as TrainContext does.
+func ResumeContext(ctx context.Context, nn *NeuralNet, path string, epochs int, trainingData []TrainingData, options ...TrainOption) (*History, error) {
+	config, err := newTrainConfig(nn, options)
+	if err != nil {
+		return nil, err
+	}
+	state, err := nn.loadCheckpoint(path, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load checkpoint, got %v", err)
+	}
+	return nn.train(ctx, state, epochs, trainingData, config)
+}
+
+func (nn *NeuralNet) saveCheckpoint(path string, state *trainingState, config *trainConfig) error {
+	model, err := nn.modelFile()
+	if err != nil {
+		return err
+	}
+	file := checkpointFile{
+		Version:   checkpointFormatVersion,
+		Model:     model,
+		Epoch:     state.epoch,
+		Step:      state.step,
+		BestEpoch: state.bestEpoch,
+	}
+	if source, ok := nn.source.(*randomSource); ok {
+		randomState := source.state
+		file.RandomState = &randomState
+	}
+	if !math.IsInf(state.bestLoss, 1) {
+		file.BestLoss = &state.bestLoss
+	}
+	for i, param := range state.bestParams {
+		file.BestParams = append(file.BestParams, paramFile{
+			Name:    nn.Params()[i].Name,
+			Rows:    param.Rows,
+			Columns: param.Columns,
+			Values:  param.FlattenedElements(),
+		})
+	}
+	if stateful, ok := nn.optimizer.(optimizer.Stateful); ok {
+		optimizerState := stateful.State()
+		file.Optimizer = &optimizerState
+	}
+	if stateful, ok := config.schedule.(schedule.Stateful); ok {
+		file.Schedule = stateful.State()
+	}
+	b, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to generate checkpoint JSON, got %v", err)
+	}
+	return writeFileAtomically(path, b)
+}
+
+// loadCheckpoint restores the params, optimizer, schedule and random
+// state saved on path and returns the training state to continue from.
+func (nn *NeuralNet) loadCheckpoint(path string, config *trainConfig) (*trainingState, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint, got %v", err)
+	}
+	var file checkpointFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint JSON, got %v", err)
+	}
+	if file.Version != checkpointFormatVersion {
+		return nil, fmt.Errorf("unsupported checkpoint format version %d, expected %d", file.Version, checkpointFormatVersion)
+	}
+	if file.Model == nil || len(file.Model.Layers) != len(nn.model.Layers()) {
+		return nil, fmt.Errorf("checkpoint doesn't have the %d layers of the network", len(nn.model.Layers()))
+	}
+	var files []paramFile
+	for _, layer := range file.Model.Layers {
+		files = append(files, layer.Params...)
+	}
+	params, err := matricesFrom(files)
+	if err != nil {
+		return nil, err
+	}
+	if err := adjust(nn.Params(), params); err != nil {
+		return nil, fmt.Errorf("failed to restore params, got %v", err)
+	}
+	if file.Optimizer != nil {
+		stateful, ok := nn.optimizer.(optimizer.Stateful)
+		if !ok {
+			return nil, fmt.Errorf("checkpoint has optimizer state but %T keeps none", nn.optimizer)
+		}
+		if err := stateful.SetState(*file.Optimizer); err != nil {
+			return nil, fmt.Errorf("failed to restore optimizer state, got %v", err)
+		}
+	}
+	if file.Schedule != nil {
+		stateful, ok := config.schedule.(schedule.Stateful)
+		if !ok {
+			return nil, fmt.Errorf("checkpoint has schedule state but %T keeps none", config.schedule)
+		}
+		if err := stateful.SetState(file.Schedule); err != nil {
+			return nil, fmt.Errorf("failed to restore schedule state, got %v", err)
+		}
+	}
+	if file.RandomState != nil {
+		source, ok := nn.source.(*randomSource)
+		if !ok {
+			return nil, fmt.Errorf("checkpoint has random state but the network source %T cannot restore it", nn.source)
+		}
+		source.state = *file.RandomState
+	}
+	state := &trainingState{epoch: file.Epoch, step: file.Step, bestLoss: math.Inf(1), bestEpoch: file.BestEpoch}
+	if file.BestLoss != nil {
+		state.bestLoss = *file.BestLoss
+	}
+	if len(file.BestParams) > 0 {
+		bestParams, err := matricesFrom(file.BestParams)
+		if err != nil {
+			return nil, err
+		}
+		// adjusting copies of the params only checks the shapes
+		params := make([]*Parameter, len(nn.Params()))
+		for i, param := range nn.Params() {
+			copied := *param
+			params[i] = &copied
+		}
+		if err := adjust(params, bestParams); err != nil {
+			return nil, fmt.Errorf("failed to restore best params, got %v", err)
+		}
+		state.bestParams = bestParams
+	}
+	return state, nil
+}
+
+func matricesFrom(files []paramFile) ([]*matrix.Matrix, error) {
+	matrices := make([]*matrix.Matrix, len(files))
+	for i, param := range files {
+		value, err := matrix.New(param.Rows, param.Columns, param.Values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build %s, got %v", param.Name, err)
+		}
+		matrices[i] = value
+	}
+	return matrices, nil
+}
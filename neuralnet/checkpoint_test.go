@@ -0,0 +1,129 @@
+package neuralnet_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/buarki/supervised-machine-learning/activation"
+	"github.com/buarki/supervised-machine-learning/matrix"
+	"github.com/buarki/supervised-machine-learning/neuralnet"
+	"github.com/buarki/supervised-machine-learning/optimizer"
+	"github.com/buarki/supervised-machine-learning/schedule"
+)
+
+// newResumableNeuralNet builds a network with Adam and the given initial params
+// along with a reduce on plateau schedule, both of which keep state during train.
+func newResumableNeuralNet(t *testing.T, weights, biases []*matrix.Matrix) (*neuralnet.NeuralNet, schedule.Schedule) {
+	adam, err := optimizer.NewAdam(0.9, 0.999, 1e-8)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:       2,
+		HiddenLayerSizes:     []int{3},
+		OutputLayerSize:      1,
+		RegularizationFactor: 0.0001,
+		Activation:           activation.NewSigmoid(),
+		Optimizer:            adam,
+	})
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if weights != nil {
+		if err := nn.AdjustWeights(weights...); err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		if err := nn.AdjustBiases(biases...); err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+	}
+	s, err := schedule.NewReduceOnPlateau(0.05, 0.5, 1, 0.01, 0.001)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	return nn, s
+}
+
+func checkpointTrainingData(t *testing.T) []neuralnet.TrainingData {
+	var data []neuralnet.TrainingData
+	for _, batch := range [][2][]float64{
+		{{0.3, 1, 0.5, 0.2, 1, 0.4}, {0.75, 0.82, 0.93}},
+		{{0.7, 0.6, 0.1, 0.9}, {0.61, 0.88}},
+	} {
+		rows := len(batch[1])
+		X, err := matrix.New(rows, 2, batch[0])
+		if err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		Y, err := matrix.New(rows, 1, batch[1])
+		if err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		data = append(data, neuralnet.TrainingData{X: X, Y: Y})
+	}
+	return data
+}
+
+func TestResumeReachesTheSameResultAsAnUninterruptedRun(t *testing.T) {
+	data := checkpointTrainingData(t)
+	uninterrupted, uninterruptedSchedule := newResumableNeuralNet(t, nil, nil)
+	initialWeights, initialBiases := uninterrupted.Weights(), uninterrupted.Biases()
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	interrupted, interruptedSchedule := newResumableNeuralNet(t, initialWeights, initialBiases)
+	if _, err := neuralnet.Train(interrupted, 12, data, neuralnet.WithSchedule(interruptedSchedule), neuralnet.WithCheckpoint(path, 5)); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if _, err := neuralnet.Train(uninterrupted, 30, data, neuralnet.WithSchedule(uninterruptedSchedule)); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	// resuming from the checkpoint of epoch 10 on a brand new network
+	resumed, resumedSchedule := newResumableNeuralNet(t, nil, nil)
+	if _, err := neuralnet.Resume(resumed, path, 30, data, neuralnet.WithSchedule(resumedSchedule)); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	for i, w := range resumed.Weights() {
+		ensureMatricesAreEqual(t, w, uninterrupted.Weights()[i])
+	}
+	for i, b := range resumed.Biases() {
+		ensureMatricesAreEqual(t, b, uninterrupted.Biases()[i])
+	}
+}
+
+func TestTrainWritesCheckpointsByInterval(t *testing.T) {
+	nn, s := newResumableNeuralNet(t, nil, nil)
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	if _, err := neuralnet.Train(nn, 1, checkpointTrainingData(t), neuralnet.WithSchedule(s), neuralnet.WithCheckpointInterval(path, time.Nanosecond)); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected checkpoint to be written, got %v", err)
+	}
+}
+
+func TestResumeWithDifferentTopology(t *testing.T) {
+	nn, s := newResumableNeuralNet(t, nil, nil)
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	if _, err := neuralnet.Train(nn, 1, checkpointTrainingData(t), neuralnet.WithSchedule(s), neuralnet.WithCheckpoint(path, 1)); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	other, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:   2,
+		HiddenLayerSizes: []int{4},
+		OutputLayerSize:  1,
+		Activation:       activation.NewSigmoid(),
+	})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	if _, err := neuralnet.Resume(other, path, 2, checkpointTrainingData(t)); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+}
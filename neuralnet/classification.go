@@ -0,0 +1,59 @@
+package neuralnet
+
+import (
+	"fmt"
+
+	"github.com/buarki/supervised-machine-learning/activation"
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+// OneHot encodes each label as a row with 1 in the column of
+// the label and 0 elsewhere. Labels must be in [0, classes).
+func OneHot(labels []int, classes int) (*matrix.Matrix, error) {
+	if classes <= 0 {
+		return nil, fmt.Errorf("classes must be > 0, received %d", classes)
+	}
+	encoded := make([]float64, len(labels)*classes)
+	for i, label := range labels {
+		if label < 0 || label >= classes {
+			return nil, fmt.Errorf("label %d of sample %d is out of range [0-%d]", label, i, classes-1)
+		}
+		encoded[i*classes+label] = 1
+	}
+	return matrix.NewFromSlice(len(labels), classes, encoded)
+}
+
+// ClassPrediction is the class predicted for a sample
+// along with the probability of every class.
+type ClassPrediction struct {
+	Label         int
+	Probabilities []float64
+}
+
+// PredictClass executes the forward process and returns the most probable
+// class of each sample. When the network doesn't end with a Softmax layer
+// its outputs are taken as logits and turned into probabilities here.
+func (nn *NeuralNet) PredictClass(X *matrix.Matrix) ([]ClassPrediction, error) {
+	prediction, err := nn.PredictBasedOn(X)
+	if err != nil {
+		return nil, err
+	}
+	layers := nn.model.Layers()
+	_, endsWithSoftmax := layers[len(layers)-1].(*Softmax)
+	values := prediction.FlattenedElements()
+	predictions := make([]ClassPrediction, prediction.Rows)
+	for i := range predictions {
+		probabilities := values[i*prediction.Columns : (i+1)*prediction.Columns]
+		if !endsWithSoftmax {
+			probabilities = activation.Softmax(probabilities)
+		}
+		label := 0
+		for j, probability := range probabilities {
+			if probability > probabilities[label] {
+				label = j
+			}
+		}
+		predictions[i] = ClassPrediction{Label: label, Probabilities: probabilities}
+	}
+	return predictions, nil
+}
@@ -0,0 +1,115 @@
+package neuralnet_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/activation"
+	"github.com/buarki/supervised-machine-learning/matrix"
+	"github.com/buarki/supervised-machine-learning/neuralnet"
+	"github.com/buarki/supervised-machine-learning/optimizer"
+)
+
+func TestOneHot(t *testing.T) {
+	encoded, err := neuralnet.OneHot([]int{2, 0, 1}, 3)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	expected, err := matrix.New(3, 3, []float64{0, 0, 1, 1, 0, 0, 0, 1, 0})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	ensureMatricesAreEqual(t, encoded, expected)
+}
+
+func TestOneHotWithLabelOutOfRange(t *testing.T) {
+	encoded, err := neuralnet.OneHot([]int{0, 3}, 3)
+	if err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+	if encoded != nil {
+		t.Errorf("expected encoded to be nil")
+	}
+}
+
+func TestPredictClassAfterTraining(t *testing.T) {
+	adam, err := optimizer.NewAdam(0.9, 0.999, 1e-8)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:   2,
+		HiddenLayerSizes: []int{8},
+		OutputLayerSize:  3,
+		LearningRate:     0.05,
+		Activation:       activation.NewTanh(),
+		SoftmaxOutput:    true,
+		Optimizer:        adam,
+	})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	// each class is a cluster around a different point
+	X, err := matrix.New(6, 2, []float64{0, 0, 0.1, 0.1, 1, 0, 0.9, 0.1, 0, 1, 0.1, 0.9})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	labels := []int{0, 0, 1, 1, 2, 2}
+	Y, err := neuralnet.OneHot(labels, 3)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	if _, err := neuralnet.Train(nn, 300, []neuralnet.TrainingData{{X: X, Y: Y}}); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	predictions, err := nn.PredictClass(X)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	for i, prediction := range predictions {
+		if prediction.Label != labels[i] {
+			t.Errorf("expected sample %d to be of class %d, got %d", i, labels[i], prediction.Label)
+		}
+		sum := 0.0
+		for _, probability := range prediction.Probabilities {
+			sum += probability
+		}
+		if math.Abs(sum-1) > acceptedError {
+			t.Errorf("expected probabilities of sample %d to sum 1, got %v", i, sum)
+		}
+	}
+}
+
+func TestPredictClassTakesOutputsAsLogitsWithoutSoftmax(t *testing.T) {
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:   2,
+		HiddenLayerSizes: []int{3},
+		OutputLayerSize:  4,
+		Activation:       activation.NewReLU(),
+		OutputActivation: activation.NewIdentity(),
+	})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	X, err := matrix.New(1, 2, []float64{0.3, 0.8})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	logits, err := nn.PredictBasedOn(X)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	predictions, err := nn.PredictClass(X)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	expected := activation.Softmax(logits.FlattenedElements())
+	for j, probability := range predictions[0].Probabilities {
+		if math.Abs(probability-expected[j]) > acceptedError {
+			t.Errorf("expected probability of class %d to be %v, got %v", j, expected[j], probability)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package neuralnet
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/buarki/supervised-machine-learning/activation"
+	"github.com/buarki/supervised-machine-learning/initializer"
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+// Dense is a fully connected layer. It computes Y = f(X*W + B) where f
+// is the activation function applied element wise.
+type Dense struct {
+	weights *Parameter
+	biases  *Parameter
+
+	activation activation.Activation
+
+	input *matrix.Matrix // X received on last forward
+	v     buffer         // X*W + B computed on last forward
+	y     buffer         // f(v) computed on last forward
+	delta buffer         // dE/dV computed on last backward
+
+	// matrices computed by backward, kept to be reused
+	inputT, weightsT, weightsGradient, biasesGradient, inputGradient buffer
+}
+
+// NewDense creates a dense layer connecting inputSize neurons to outputSize
+// neurons with weights drawn from random by the default initializer of the
+// given activation and zero biases. Biases are a single row that is summed
+// to every sample, so the layer accepts batches of any size.
+func NewDense(inputSize, outputSize int, a activation.Activation, random *rand.Rand) (*Dense, error) {
+	return NewDenseWithInitializers(inputSize, outputSize, a, nil, nil, random)
+}
+
+// NewDenseWithInitializers creates a dense layer whose weights and biases
+// are given by the given initializers. Nil initializers fall back to the
+// defaults of NewDense.
+func NewDenseWithInitializers(inputSize, outputSize int, a activation.Activation, weightsInitializer, biasesInitializer initializer.Initializer, random *rand.Rand) (*Dense, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	if random == nil {
+		return nil, errors.New("random cannot be nil")
+	}
+	if weightsInitializer == nil {
+		weightsInitializer = defaultWeightsInitializerFor(a)
+	}
+	if biasesInitializer == nil {
+		biasesInitializer = initializer.NewZeros()
+	}
+	weights, err := weightsInitializer.Initialize(inputSize, outputSize, random)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate weights, got %v", err)
+	}
+	biases, err := biasesInitializer.Initialize(1, outputSize, random)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate biases, got %v", err)
+	}
+	return denseFrom(weights, biases, a), nil
+}
+
+// defaultWeightsInitializerFor returns He for ReLU like activations, LeCun
+// normal for SELU, which needs it to self normalize, and Glorot otherwise.
+func defaultWeightsInitializerFor(a activation.Activation) initializer.Initializer {
+	switch a.Name {
+	case "relu", "leaky_relu", "elu", "gelu", "swish":
+		return initializer.NewHeNormal()
+	case "selu":
+		return initializer.NewLeCunNormal()
+	}
+	return initializer.NewGlorotUniform()
+}
+
+func denseFrom(weights, biases *matrix.Matrix, a activation.Activation) *Dense {
+	return &Dense{
+		weights:    &Parameter{Name: ParameterWeights, Value: weights},
+		biases:     &Parameter{Name: ParameterBiases, Value: biases},
+		activation: a,
+	}
+}
+
+func (d *Dense) Weights() *matrix.Matrix {
+	return d.weights.Value
+}
+
+func (d *Dense) Biases() *matrix.Matrix {
+	return d.biases.Value
+}
+
+func (d *Dense) Activation() activation.Activation {
+	return d.activation
+}
+
+// PreActivation returns X*W + B computed on the last forward.
+func (d *Dense) PreActivation() *matrix.Matrix {
+	return d.v.m
+}
+
+// Delta returns dE/dV computed on the last backward.
+func (d *Dense) Delta() *matrix.Matrix {
+	return d.delta.m
+}
+
+func (d *Dense) Params() []*Parameter {
+	return []*Parameter{d.weights, d.biases}
+}
+
+// Forward computes f(X*W + B) and keeps the intermediate
+// matrices for the backward process.
+func (d *Dense) Forward(X *matrix.Matrix) (*matrix.Matrix, error) {
+	y, err := d.forwardInto(&d.v, &d.y, X)
+	if err != nil {
+		return nil, err
+	}
+	d.input = X
+	return y, nil
+}
+
+// predict runs forward on new matrices without caching anything.
+func (d *Dense) predict(X *matrix.Matrix) (*matrix.Matrix, error) {
+	var v, y buffer
+	return d.forwardInto(&v, &y, X)
+}
+
+// forwardInto computes X*W + B on v and its activation on y.
+func (d *Dense) forwardInto(vBuffer, yBuffer *buffer, X *matrix.Matrix) (*matrix.Matrix, error) {
+	if X == nil {
+		return nil, errors.New("param x cannot be nil")
+	}
+	v, err := vBuffer.shaped(X.Rows, d.weights.Value.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create v, got %v", err)
+	}
+	if err := matrix.MulInto(v, X, d.weights.Value); err != nil {
+		return nil, fmt.Errorf("failed to compute x*w, got %v", err)
+	}
+	if err := matrix.AddRowVectorInto(v, v, d.biases.Value); err != nil {
+		return nil, fmt.Errorf("failed to compute x*w + b, got %v", err)
+	}
+	y, err := yBuffer.shaped(v.Rows, v.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create y, got %v", err)
+	}
+	if err := matrix.ApplyInto(y, v, d.activation.Function); err != nil {
+		return nil, fmt.Errorf("failed to compute activation, got %v", err)
+	}
+	return y, nil
+}
+
+// Backward computes delta = dE/dY (hadamard) f'(V), stores X^T*delta and the sum
+// of delta rows as the gradients of weights and biases and returns delta*W^T.
+func (d *Dense) Backward(outputGradient *matrix.Matrix) (*matrix.Matrix, error) {
+	if d.v.m == nil {
+		return nil, errors.New("backward called before forward")
+	}
+	delta, err := d.delta.shaped(d.v.m.Rows, d.v.m.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create delta, got %v", err)
+	}
+	if err := d.activationPrimeInto(delta); err != nil {
+		return nil, err
+	}
+	if err := delta.HadamardInPlace(outputGradient); err != nil {
+		return nil, fmt.Errorf("failed to compute delta, got %v", err)
+	}
+	weights := d.weights.Value
+	inputT, err := d.inputT.shaped(d.input.Columns, d.input.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create x^T, got %v", err)
+	}
+	if err := matrix.TransposeInto(inputT, d.input); err != nil {
+		return nil, fmt.Errorf("failed to compute x^T, got %v", err)
+	}
+	dEdW, err := d.weightsGradient.shaped(weights.Rows, weights.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dEdW, got %v", err)
+	}
+	if err := matrix.MulInto(dEdW, inputT, delta); err != nil {
+		return nil, fmt.Errorf("failed to compute dEdW, got %v", err)
+	}
+	weightsT, err := d.weightsT.shaped(weights.Columns, weights.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create W^T, got %v", err)
+	}
+	if err := matrix.TransposeInto(weightsT, weights); err != nil {
+		return nil, fmt.Errorf("failed to compute W^T, got %v", err)
+	}
+	inputGradient, err := d.inputGradient.shaped(delta.Rows, weights.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create delta*W^T, got %v", err)
+	}
+	if err := matrix.MulInto(inputGradient, delta, weightsT); err != nil {
+		return nil, fmt.Errorf("failed to compute delta*W^T, got %v", err)
+	}
+	dEdB, err := d.biasesGradient.shaped(1, delta.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dEdB, got %v", err)
+	}
+	if err := matrix.SumColumnsInto(dEdB, delta); err != nil {
+		return nil, fmt.Errorf("failed to compute dEdB, got %v", err)
+	}
+	d.weights.Gradient = dEdW
+	d.biases.Gradient = dEdB
+	return inputGradient, nil
+}
+
+// activationPrimeInto writes f'(V) on dst, from Y when the activation
+// allows it so f doesn't need to be evaluated again.
+func (d *Dense) activationPrimeInto(dst *matrix.Matrix) error {
+	if d.activation.DerivativeFromOutput != nil {
+		if err := matrix.ApplyInto(dst, d.y.m, d.activation.DerivativeFromOutput); err != nil {
+			return fmt.Errorf("failed to compute activation prime from y, got %v", err)
+		}
+		return nil
+	}
+	if err := matrix.ApplyInto(dst, d.v.m, d.activation.Derivative); err != nil {
+		return fmt.Errorf("failed to compute activation prime of v, got %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package neuralnet
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// TrainStep lets tests run a single train step.
+var TrainStep = (*NeuralNet).trainStep
+
+// EpochRunner lets tests run a train one epoch at a time, returning the
+// function that runs the next epoch. The history has room for every
+// epoch, so recording them doesn't allocate.
+func EpochRunner(nn *NeuralNet, epochs int, trainingData []TrainingData, options ...TrainOption) (func() error, error) {
+	config, err := newTrainConfig(nn, options)
+	if err != nil {
+		return nil, err
+	}
+	flat, history, err := nn.startTrain(trainingData, config)
+	if err != nil {
+		return nil, err
+	}
+	history.Epochs = make([]EpochHistory, 0, epochs)
+	state := &trainingState{bestLoss: math.Inf(1)}
+	lastCheckpoint := time.Now()
+	return func() error {
+		_, err := nn.trainEpoch(context.Background(), state, epochs, trainingData, flat, config, &lastCheckpoint)
+		return err
+	}, nil
+}
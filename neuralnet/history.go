@@ -0,0 +1,208 @@
+package neuralnet
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// History holds what happened on each epoch of a train.
+type History struct {
+	Epochs []EpochHistory
+}
+
+// EpochHistory is the record of a finished epoch.
+type EpochHistory struct {
+	Epoch        int     // Epochs finished, starting at 1
+	Loss         float64 // Average loss of the batches, weighted by their samples
+	LearningRate float64 // Learning rate of the last batch
+
+	// TrainMetrics are the metrics given by WithMetrics measured
+	// on the training batches, nil when there are none.
+	TrainMetrics map[string]float64
+
+	// ValidationLoss and ValidationMetrics are only set
+	// for the epochs the network is validated on.
+	ValidationLoss    *float64
+	ValidationMetrics map[string]float64
+
+	// GradientNorm is the L2 norm of the gradients of all params,
+	// averaged over the batches.
+	GradientNorm float64
+
+	// WallTime is how long the epoch took, validation included.
+	WallTime time.Duration
+}
+
+// epochRecord is how an epoch is written on JSON.
+type epochRecord struct {
+	Epoch             int                `json:"epoch"`
+	Loss              float64            `json:"loss"`
+	TrainMetrics      map[string]float64 `json:"trainMetrics,omitempty"`
+	ValidationLoss    *float64           `json:"validationLoss,omitempty"`
+	ValidationMetrics map[string]float64 `json:"validationMetrics,omitempty"`
+	LearningRate      float64            `json:"learningRate"`
+	GradientNorm      float64            `json:"gradientNorm"`
+	WallTimeSeconds   float64            `json:"wallTimeSeconds"`
+}
+
+// WriteJSON writes the history as a JSON object with one entry per
+// epoch on "epochs", the wall time given in seconds.
+func (h *History) WriteJSON(w io.Writer) error {
+	records := make([]epochRecord, len(h.Epochs))
+	for i, epoch := range h.Epochs {
+		records[i] = epochRecord{
+			Epoch:             epoch.Epoch,
+			Loss:              epoch.Loss,
+			TrainMetrics:      epoch.TrainMetrics,
+			ValidationLoss:    epoch.ValidationLoss,
+			ValidationMetrics: epoch.ValidationMetrics,
+			LearningRate:      epoch.LearningRate,
+			GradientNorm:      epoch.GradientNorm,
+			WallTimeSeconds:   epoch.WallTime.Seconds(),
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(struct {
+		Epochs []epochRecord `json:"epochs"`
+	}{records}); err != nil {
+		return fmt.Errorf("failed to write history JSON, got %v", err)
+	}
+	return nil
+}
+
+// WriteCSV writes the history with a header and one row per epoch. Each
+// metric seen on any epoch has a column, sorted by name, for its value on
+// the training batches and a "validation_" one for its value on validation.
+// Values not known on an epoch are left empty.
+func (h *History) WriteCSV(w io.Writer) error {
+	var trainMetrics, validationMetrics []map[string]float64
+	for _, epoch := range h.Epochs {
+		trainMetrics = append(trainMetrics, epoch.TrainMetrics)
+		validationMetrics = append(validationMetrics, epoch.ValidationMetrics)
+	}
+	trainNames, validationNames := metricNames(trainMetrics), metricNames(validationMetrics)
+
+	writer := csv.NewWriter(w)
+	header := []string{"epoch", "loss", "validation_loss", "learning_rate", "gradient_norm", "wall_time_seconds"}
+	header = append(header, trainNames...)
+	for _, name := range validationNames {
+		header = append(header, "validation_"+name)
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write history CSV header, got %v", err)
+	}
+	for _, epoch := range h.Epochs {
+		validationLoss := ""
+		if epoch.ValidationLoss != nil {
+			validationLoss = formatFloat(*epoch.ValidationLoss)
+		}
+		row := []string{
+			strconv.Itoa(epoch.Epoch),
+			formatFloat(epoch.Loss),
+			validationLoss,
+			formatFloat(epoch.LearningRate),
+			formatFloat(epoch.GradientNorm),
+			formatFloat(epoch.WallTime.Seconds()),
+		}
+		row = appendMetrics(row, trainNames, epoch.TrainMetrics)
+		row = appendMetrics(row, validationNames, epoch.ValidationMetrics)
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write history CSV row of epoch %d, got %v", epoch.Epoch, err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write history CSV, got %v", err)
+	}
+	return nil
+}
+
+// metricNames returns the names of the metrics seen on any epoch, sorted.
+func metricNames(epochs []map[string]float64) []string {
+	seen := map[string]bool{}
+	for _, metrics := range epochs {
+		for name := range metrics {
+			seen[name] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// appendMetrics appends the value of each named metric to row,
+// or an empty value for the ones not measured.
+func appendMetrics(row, names []string, metrics map[string]float64) []string {
+	for _, name := range names {
+		value, ok := metrics[name]
+		if !ok {
+			row = append(row, "")
+			continue
+		}
+		row = append(row, formatFloat(value))
+	}
+	return row
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// historyRecorder is the callback building the history returned by Train.
+type historyRecorder struct {
+	BaseCallback
+	history         *History
+	epochStart      time.Time
+	sumOfNorms      float64
+	amountOfBatches int
+}
+
+func (r *historyRecorder) OnEpochBegin(event *TrainEvent) error {
+	r.epochStart = time.Now()
+	r.sumOfNorms, r.amountOfBatches = 0, 0
+	return nil
+}
+
+func (r *historyRecorder) OnBatchEnd(event *TrainEvent) error {
+	sumOfSquares := 0.0
+	for i, gradient := range event.Gradients {
+		norm, err := gradient.Norm(2)
+		if err != nil {
+			return fmt.Errorf("failed to compute norm of gradient %d, got %v", i, err)
+		}
+		sumOfSquares += norm * norm
+	}
+	r.sumOfNorms += math.Sqrt(sumOfSquares)
+	r.amountOfBatches++
+	return nil
+}
+
+func (r *historyRecorder) OnEpochEnd(event *TrainEvent) error {
+	epoch := EpochHistory{
+		Epoch:        event.Epoch + 1,
+		Loss:         event.Loss,
+		LearningRate: event.LearningRate,
+		TrainMetrics: event.TrainMetrics,
+		WallTime:     time.Since(r.epochStart),
+	}
+	if r.amountOfBatches > 0 {
+		epoch.GradientNorm = r.sumOfNorms / float64(r.amountOfBatches)
+	}
+	if event.Validation != nil {
+		validationLoss := event.Validation.Loss
+		epoch.ValidationLoss = &validationLoss
+		epoch.ValidationMetrics = event.Validation.Metrics
+	}
+	r.history.Epochs = append(r.history.Epochs, epoch)
+	return nil
+}
@@ -0,0 +1,156 @@
+package neuralnet_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/metric"
+	"github.com/buarki/supervised-machine-learning/neuralnet"
+)
+
+func trainWithHistory(t *testing.T) *neuralnet.History {
+	nn := newConstantNeuralNet(t)
+	training, validation := divergingData(t)
+	history, err := neuralnet.Train(nn, 3, training,
+		neuralnet.WithValidation(validation, 2),
+		neuralnet.WithMetrics(metric.NewRMSE()),
+	)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	return history
+}
+
+func TestTrainReturnsHistory(t *testing.T) {
+	history := trainWithHistory(t)
+
+	if len(history.Epochs) != 3 {
+		t.Fatalf("expected 3 epochs, got %d", len(history.Epochs))
+	}
+	for i, epoch := range history.Epochs {
+		if epoch.Epoch != i+1 {
+			t.Errorf("expected epoch %d to be numbered %d, got %d", i, i+1, epoch.Epoch)
+		}
+		if epoch.Loss <= 0 || epoch.GradientNorm <= 0 || epoch.WallTime <= 0 {
+			t.Errorf("expected loss, gradient norm and wall time of epoch %d to be > 0, got %+v", i+1, epoch)
+		}
+		if epoch.LearningRate != 0.5 {
+			t.Errorf("expected learning rate of epoch %d to be 0.5, got %v", i+1, epoch.LearningRate)
+		}
+		validated := epoch.Epoch == 2
+		if (epoch.ValidationLoss != nil) != validated {
+			t.Errorf("expected epoch %d to be validated: %v, got validation loss %v", i+1, validated, epoch.ValidationLoss)
+		}
+		if _, ok := epoch.ValidationMetrics["rmse"]; ok != validated {
+			t.Errorf("expected epoch %d to have validation rmse: %v, got %v", i+1, validated, epoch.ValidationMetrics)
+		}
+		if _, ok := epoch.TrainMetrics["rmse"]; !ok {
+			t.Errorf("expected epoch %d to have training rmse, got %v", i+1, epoch.TrainMetrics)
+		}
+	}
+	if history.Epochs[1].Loss >= history.Epochs[0].Loss {
+		t.Errorf("expected loss to decrease, got %v and %v", history.Epochs[0].Loss, history.Epochs[1].Loss)
+	}
+}
+
+func TestHistoryHasTrainMetricsWithoutValidation(t *testing.T) {
+	nn := newConstantNeuralNet(t)
+	training, _ := divergingData(t)
+
+	history, err := neuralnet.Train(nn, 2, training, neuralnet.WithMetrics(metric.NewRMSE()))
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	// with zero params the first predictions are 0.5 while 1 is expected
+	if rmse, ok := history.Epochs[0].TrainMetrics["rmse"]; !ok || math.Abs(rmse-0.5) > 1e-9 {
+		t.Errorf("expected training rmse of first epoch to be 0.5, got %v", history.Epochs[0].TrainMetrics)
+	}
+	if rmse := history.Epochs[1].TrainMetrics["rmse"]; rmse >= 0.5 {
+		t.Errorf("expected training rmse to decrease, got %v", rmse)
+	}
+	if history.Epochs[1].ValidationMetrics != nil {
+		t.Errorf("expected no validation metrics, got %v", history.Epochs[1].ValidationMetrics)
+	}
+}
+
+func TestHistoryWriteCSV(t *testing.T) {
+	history := trainWithHistory(t)
+	var b bytes.Buffer
+
+	if err := history.WriteCSV(&b); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	rows, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	expectedHeader := []string{"epoch", "loss", "validation_loss", "learning_rate", "gradient_norm", "wall_time_seconds", "rmse", "validation_rmse"}
+	if len(rows) != 4 {
+		t.Fatalf("expected header and 3 rows, got %d rows", len(rows))
+	}
+	for i, column := range expectedHeader {
+		if rows[0][i] != column {
+			t.Errorf("expected column %d to be %s, got %s", i, column, rows[0][i])
+		}
+	}
+	if rows[1][0] != "1" || rows[1][2] != "" || rows[1][6] == "" || rows[1][7] != "" {
+		t.Errorf("expected first epoch with training rmse and without validation, got %v", rows[1])
+	}
+	if rows[2][2] == "" || rows[2][6] == "" || rows[2][7] == "" {
+		t.Errorf("expected second epoch with validation, got %v", rows[2])
+	}
+}
+
+func TestHistoryWriteJSON(t *testing.T) {
+	history := trainWithHistory(t)
+	var b bytes.Buffer
+
+	if err := history.WriteJSON(&b); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	var written struct {
+		Epochs []struct {
+			Epoch             int                `json:"epoch"`
+			Loss              float64            `json:"loss"`
+			TrainMetrics      map[string]float64 `json:"trainMetrics"`
+			ValidationLoss    *float64           `json:"validationLoss"`
+			ValidationMetrics map[string]float64 `json:"validationMetrics"`
+			WallTimeSeconds   float64            `json:"wallTimeSeconds"`
+		} `json:"epochs"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &written); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if len(written.Epochs) != 3 {
+		t.Fatalf("expected 3 epochs, got %d", len(written.Epochs))
+	}
+	second := written.Epochs[1]
+	if second.Epoch != 2 || second.Loss != history.Epochs[1].Loss || second.ValidationLoss == nil ||
+		second.TrainMetrics["rmse"] != history.Epochs[1].TrainMetrics["rmse"] || second.ValidationMetrics["rmse"] != history.Epochs[1].ValidationMetrics["rmse"] {
+		t.Errorf("expected second epoch to be written as %+v, got %+v", history.Epochs[1], second)
+	}
+	if written.Epochs[0].ValidationLoss != nil {
+		t.Errorf("expected first epoch to have no validation loss")
+	}
+}
+
+func TestInterruptedTrainReturnsHistory(t *testing.T) {
+	nn := newConstantNeuralNet(t)
+	training, _ := divergingData(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	history, err := neuralnet.TrainContext(ctx, nn, 10, training, neuralnet.WithCallbacks(&cancelingCallback{cancel: cancel, steps: 2}))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected err to be context canceled, got %v", err)
+	}
+	if history == nil || len(history.Epochs) != 2 {
+		t.Errorf("expected history of 2 epochs, got %+v", history)
+	}
+}
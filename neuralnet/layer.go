@@ -0,0 +1,81 @@
+package neuralnet
+
+import "github.com/buarki/supervised-machine-learning/matrix"
+
+// Names of the parameters held by layers.
+const (
+	ParameterWeights = "weights"
+	ParameterBiases  = "biases"
+	ParameterAlphas  = "alphas"
+)
+
+// Parameter is a trainable matrix of a layer along with the
+// gradient computed for it by the last backward pass.
+type Parameter struct {
+	Name     string
+	Value    *matrix.Matrix
+	Gradient *matrix.Matrix
+}
+
+// Layer is a building block of a neural network. Forward receives the
+// output of the previous layer and caches whatever Backward needs, while
+// Backward receives dE/dY of this layer, fills the gradient of each
+// parameter and returns dE/dX so it can be given to the previous layer.
+// The matrices returned by both, as well as the gradients, may be reused
+// by the next calls, so they must be copied to be kept.
+type Layer interface {
+	Forward(X *matrix.Matrix) (*matrix.Matrix, error)
+	Backward(outputGradient *matrix.Matrix) (*matrix.Matrix, error)
+	Params() []*Parameter
+}
+
+// predictor is implemented by layers which can run forward on new
+// matrices without caching anything, so predictions can run concurrently
+// and don't overwrite the outputs of previous ones.
+type predictor interface {
+	predict(X *matrix.Matrix) (*matrix.Matrix, error)
+}
+
+// buffer is a matrix computed on every batch, kept to be reused by the next
+// batches so it is only allocated again when the batch size grows. Its
+// elements are also on data, row after row, to be set directly.
+type buffer struct {
+	m    *matrix.Matrix
+	data []float64
+
+	// shapes are the matrices of the last shapes asked for, all on storage,
+	// so a smaller last batch of each epoch doesn't allocate either
+	storage []float64
+	shapes  []*matrix.Matrix
+}
+
+// maxShapes is how many shapes a buffer keeps a matrix for.
+const maxShapes = 4
+
+// shaped returns the matrix of the buffer with the given shape. Matrices
+// of other shapes returned before share its elements.
+func (b *buffer) shaped(rows, columns int) (*matrix.Matrix, error) {
+	if b.m != nil && b.m.Rows == rows && b.m.Columns == columns {
+		return b.m, nil
+	}
+	size := rows * columns
+	for _, m := range b.shapes {
+		if m.Rows == rows && m.Columns == columns {
+			b.m, b.data = m, b.storage[:size]
+			return m, nil
+		}
+	}
+	if size > len(b.storage) {
+		b.storage, b.shapes = make([]float64, size), nil
+	}
+	if len(b.shapes) == maxShapes {
+		b.shapes = b.shapes[:0]
+	}
+	m, err := matrix.NewFromSlice(rows, columns, b.storage[:size])
+	if err != nil {
+		return nil, err
+	}
+	b.m, b.data = m, b.storage[:size]
+	b.shapes = append(b.shapes, m)
+	return m, nil
+}
@@ -0,0 +1,267 @@
+package neuralnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/buarki/supervised-machine-learning/activation"
+	"github.com/buarki/supervised-machine-learning/loss"
+	"github.com/buarki/supervised-machine-learning/matrix"
+	"github.com/buarki/supervised-machine-learning/optimizer"
+)
+
+// modelFormatVersion is increased whenever modelFile changes in a way
+// older loaders can't read.
+const modelFormatVersion = 2
+
+// Layer types written on model files.
+const (
+	layerTypeDense   = "dense"
+	layerTypePReLU   = "prelu"
+	layerTypeSoftmax = "softmax"
+)
+
+// modelFile describes a neural net with everything needed to build it again.
+type modelFile struct {
+	Version              int              `json:"version"`
+	LearningRate         float64          `json:"learningRate"`
+	RegularizationFactor float64          `json:"regularizationFactor"`
+	Loss                 lossFile         `json:"loss"`
+	Optimizer            optimizer.Config `json:"optimizer"`
+	Layers               []layerFile      `json:"layers"`
+}
+
+type lossFile struct {
+	Name      string  `json:"name"`
+	Parameter float64 `json:"parameter,omitempty"` // Delta of huber
+}
+
+type layerFile struct {
+	Type       string          `json:"type"`
+	Activation *activationFile `json:"activation,omitempty"`
+	Params     []paramFile     `json:"params,omitempty"`
+}
+
+type activationFile struct {
+	Name  string  `json:"name"`
+	Alpha float64 `json:"alpha,omitempty"`
+}
+
+type paramFile struct {
+	Name    string    `json:"name"`
+	Rows    int       `json:"rows"`
+	Columns int       `json:"columns"`
+	Values  []float64 `json:"values"`
+}
+
+// ToJSON can be used to export the neural net state. The output holds the
+// topology, the shape and values of every param, the activation of each
+// layer, the loss, the optimizer and hyperparams, and can be loaded back
+// with FromJSON. Activations must be known by activation.ByName and the
+// optimizer must implement optimizer.Configurable. Its state is only kept
+// by checkpoints.
+func (nn *NeuralNet) ToJSON() (string, error) {
+	file, err := nn.modelFile()
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate neural net JSON, got %v", err)
+	}
+	return string(b), nil
+}
+
+// FromJSON builds the neural net described by the output of ToJSON.
+func FromJSON(s string) (*NeuralNet, error) {
+	var file modelFile
+	if err := json.Unmarshal([]byte(s), &file); err != nil {
+		return nil, fmt.Errorf("failed to parse neural net JSON, got %v", err)
+	}
+	if file.Version != modelFormatVersion {
+		return nil, fmt.Errorf("unsupported model format version %d, expected %d", file.Version, modelFormatVersion)
+	}
+	l, err := loss.ByName(file.Loss.Name, file.Loss.Parameter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build loss, got %v", err)
+	}
+	opt, err := optimizer.FromConfig(file.Optimizer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build optimizer, got %v", err)
+	}
+	layers := make([]Layer, len(file.Layers))
+	for i, layer := range file.Layers {
+		layers[i], err = layer.build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build layer %d, got %v", i, err)
+		}
+	}
+	if err := checkWidths(layers); err != nil {
+		return nil, err
+	}
+	return NewWithConfig(Config{
+		LearningRate:         file.LearningRate,
+		RegularizationFactor: file.RegularizationFactor,
+		Layers:               layers,
+		Loss:                 l,
+		Optimizer:            opt,
+	})
+}
+
+// Save writes the output of ToJSON on the given path. The file is
+// replaced atomically, so a crash never leaves it half written.
+func (nn *NeuralNet) Save(path string) error {
+	s, err := nn.ToJSON()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(path, []byte(s))
+}
+
+// Load reads a neural net saved with Save.
+func Load(path string) (*NeuralNet, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model file, got %v", err)
+	}
+	return FromJSON(string(b))
+}
+
+func (nn *NeuralNet) modelFile() (*modelFile, error) {
+	file := &modelFile{
+		Version:              modelFormatVersion,
+		LearningRate:         nn.learningRate,
+		RegularizationFactor: nn.regularizationFactor,
+		Loss:                 lossFile{Name: nn.loss.Name()},
+	}
+	if huber, ok := nn.loss.(*loss.Huber); ok {
+		file.Loss.Parameter = huber.Delta()
+	}
+	configurable, ok := nn.optimizer.(optimizer.Configurable)
+	if !ok {
+		return nil, fmt.Errorf("optimizer of type %T cannot be exported", nn.optimizer)
+	}
+	file.Optimizer = configurable.Config()
+	for i, layer := range nn.model.Layers() {
+		var lf layerFile
+		switch l := layer.(type) {
+		case *Dense:
+			lf.Type = layerTypeDense
+			// functions given by the caller cannot be known by name, so they couldn't be loaded
+			if _, err := activation.ByName(l.activation.Name, l.activation.Alpha); err != nil {
+				return nil, fmt.Errorf("activation of layer %d cannot be exported, got %v", i, err)
+			}
+			lf.Activation = &activationFile{Name: l.activation.Name, Alpha: l.activation.Alpha}
+		case *PReLU:
+			lf.Type = layerTypePReLU
+		case *Softmax:
+			lf.Type = layerTypeSoftmax
+		default:
+			return nil, fmt.Errorf("layer %d of type %T cannot be exported", i, layer)
+		}
+		for _, param := range layer.Params() {
+			lf.Params = append(lf.Params, paramFile{
+				Name:    param.Name,
+				Rows:    param.Value.Rows,
+				Columns: param.Value.Columns,
+				Values:  param.Value.FlattenedElements(),
+			})
+		}
+		file.Layers = append(file.Layers, lf)
+	}
+	return file, nil
+}
+
+func (lf layerFile) build() (Layer, error) {
+	params := map[string]*matrix.Matrix{}
+	for _, param := range lf.Params {
+		value, err := matrix.New(param.Rows, param.Columns, param.Values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build %s, got %v", param.Name, err)
+		}
+		params[param.Name] = value
+	}
+	switch lf.Type {
+	case layerTypeDense:
+		if lf.Activation == nil {
+			return nil, fmt.Errorf("dense layer has no activation")
+		}
+		a, err := activation.ByName(lf.Activation.Name, lf.Activation.Alpha)
+		if err != nil {
+			return nil, err
+		}
+		weights, biases := params[ParameterWeights], params[ParameterBiases]
+		if weights == nil || biases == nil {
+			return nil, fmt.Errorf("dense layer must have weights and biases")
+		}
+		if biases.Rows != 1 || biases.Columns != weights.Columns {
+			return nil, fmt.Errorf("biases must be (1x%d), received (%dx%d)", weights.Columns, biases.Rows, biases.Columns)
+		}
+		return denseFrom(weights, biases, a), nil
+	case layerTypePReLU:
+		alphas := params[ParameterAlphas]
+		if alphas == nil || alphas.Rows != 1 {
+			return nil, fmt.Errorf("prelu layer must have a row of alphas")
+		}
+		return &PReLU{alphas: &Parameter{Name: ParameterAlphas, Value: alphas}}, nil
+	case layerTypeSoftmax:
+		return NewSoftmax(), nil
+	}
+	return nil, fmt.Errorf("unknown layer type %q", lf.Type)
+}
+
+// checkWidths ensures every layer receives as many columns as
+// the layers before it output, so a corrupted file fails on load
+// instead of on the first prediction.
+func checkWidths(layers []Layer) error {
+	width := 0 // unknown until a layer with params sets it
+	for i, layer := range layers {
+		in, out := widthsOf(layer)
+		if in > 0 && width > 0 && in != width {
+			return fmt.Errorf("layer %d expects %d inputs, but the layers before it output %d", i, in, width)
+		}
+		if out > 0 {
+			width = out
+		}
+	}
+	return nil
+}
+
+// widthsOf returns how many columns layer receives and outputs,
+// or 0 when they are whatever it is given.
+func widthsOf(layer Layer) (int, int) {
+	switch l := layer.(type) {
+	case *Dense:
+		return l.weights.Value.Rows, l.weights.Value.Columns
+	case *PReLU:
+		return l.alphas.Value.Columns, l.alphas.Value.Columns
+	}
+	return 0, 0
+}
+
+// writeFileAtomically writes data on a temporary file in the same
+// directory and renames it to path, which replaces it atomically.
+func writeFileAtomically(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file, got %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary file, got %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temporary file, got %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file, got %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s, got %v", path, err)
+	}
+	return nil
+}
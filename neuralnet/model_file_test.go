@@ -0,0 +1,238 @@
+package neuralnet_test
+
+import (
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/activation"
+	"github.com/buarki/supervised-machine-learning/loss"
+	"github.com/buarki/supervised-machine-learning/matrix"
+	"github.com/buarki/supervised-machine-learning/neuralnet"
+	"github.com/buarki/supervised-machine-learning/optimizer"
+)
+
+func ensureSamePredictions(t *testing.T, original, loaded *neuralnet.NeuralNet) {
+	X, err := matrix.New(3, 2, []float64{0.3, 1, -0.5, 0.2, 1, -0.4})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	expected, err := original.PredictBasedOn(X)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	received, err := loaded.PredictBasedOn(X)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	ensureMatricesAreEqual(t, received, expected)
+}
+
+func TestFromJSONRebuildsNeuralNet(t *testing.T) {
+	huber, err := loss.NewHuber(0.3)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	adam, err := optimizer.NewAdam(0.8, 0.99, 1e-6)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:       2,
+		HiddenLayerSizes:     []int{4, 3},
+		OutputLayerSize:      2,
+		LearningRate:         0.01,
+		RegularizationFactor: 0.001,
+		Activation:           activation.NewLeakyReLU(0.02),
+		OutputActivation:     activation.NewIdentity(),
+		Loss:                 huber,
+		Optimizer:            adam,
+	})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	nnJSON, err := nn.ToJSON()
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	loaded, err := neuralnet.FromJSON(nnJSON)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if loaded.LearningRate() != 0.01 || loaded.RegularizationFactor() != 0.001 {
+		t.Errorf("expected hyperparams to be 0.01 and 0.001, got %v and %v", loaded.LearningRate(), loaded.RegularizationFactor())
+	}
+	if l, ok := loaded.Loss().(*loss.Huber); !ok || l.Delta() != 0.3 {
+		t.Errorf("expected loss to be huber with delta 0.3, got %s", loaded.Loss().Name())
+	}
+	if o, ok := loaded.Optimizer().(*optimizer.Adam); !ok || !reflect.DeepEqual(o.Config(), adam.Config()) {
+		t.Errorf("expected optimizer to be %+v, got %T", adam.Config(), loaded.Optimizer())
+	}
+	for i, layer := range loaded.Layers() {
+		a := layer.(*neuralnet.Dense).Activation()
+		expected := nn.Layers()[i].(*neuralnet.Dense).Activation()
+		if a.Name != expected.Name || a.Alpha != expected.Alpha {
+			t.Errorf("expected layer %d to use %s(%v), got %s(%v)", i, expected.Name, expected.Alpha, a.Name, a.Alpha)
+		}
+	}
+	for i, w := range loaded.Weights() {
+		ensureMatricesAreEqual(t, w, nn.Weights()[i])
+	}
+	for i, b := range loaded.Biases() {
+		ensureMatricesAreEqual(t, b, nn.Biases()[i])
+	}
+	ensureSamePredictions(t, nn, loaded)
+}
+
+func TestFromJSONRebuildsPReLUAndSoftmaxLayers(t *testing.T) {
+	random := rand.New(neuralnet.NewSource(1))
+	dense, err := neuralnet.NewDense(2, 3, activation.NewIdentity(), random)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	prelu, err := neuralnet.NewPReLU(3, 0.3)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	output, err := neuralnet.NewDense(3, 4, activation.NewIdentity(), random)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		Layers: []neuralnet.Layer{dense, prelu, output, neuralnet.NewSoftmax()},
+		Loss:   loss.NewCategoricalCrossEntropy(),
+	})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	nnJSON, err := nn.ToJSON()
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	loaded, err := neuralnet.FromJSON(nnJSON)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if _, ok := loaded.Layers()[1].(*neuralnet.PReLU); !ok {
+		t.Errorf("expected layer 1 to be prelu")
+	}
+	if _, ok := loaded.Layers()[3].(*neuralnet.Softmax); !ok {
+		t.Errorf("expected layer 3 to be softmax")
+	}
+	ensureSamePredictions(t, nn, loaded)
+}
+
+func TestFromJSONWithUnsupportedVersion(t *testing.T) {
+	nn, err := neuralnet.FromJSON(`{"learningRate":0.001,"regularizationFactor":0.0001,"weights":[[1,2,3,4,5,6],[7,8,9]],"biases":[[1,2,3],[7]]}`)
+	if err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("expected err about the version, got %v", err)
+	}
+	if nn != nil {
+		t.Errorf("expected nn to be nil")
+	}
+}
+
+func TestFromJSONWithInvalidShape(t *testing.T) {
+	nn, err := neuralnet.FromJSON(`{"version":2,"loss":{"name":"mse"},"optimizer":{"name":"sgd"},"layers":[{"type":"dense","activation":{"name":"relu"},` +
+		`"params":[{"name":"weights","rows":2,"columns":2,"values":[1,2,3]},{"name":"biases","rows":1,"columns":2,"values":[1,2]}]}]}`)
+	if err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+	if nn != nil {
+		t.Errorf("expected nn to be nil")
+	}
+}
+
+func TestFromJSONWithMismatchedLayers(t *testing.T) {
+	testCases := []struct {
+		name   string
+		layers string
+	}{
+		{"dense after dense", `{"type":"dense","activation":{"name":"relu"},` +
+			`"params":[{"name":"weights","rows":2,"columns":3,"values":[1,2,3,4,5,6]},{"name":"biases","rows":1,"columns":3,"values":[1,2,3]}]},` +
+			`{"type":"dense","activation":{"name":"sigmoid"},` +
+			`"params":[{"name":"weights","rows":2,"columns":1,"values":[1,2]},{"name":"biases","rows":1,"columns":1,"values":[1]}]}`},
+		{"prelu after softmax", `{"type":"dense","activation":{"name":"identity"},` +
+			`"params":[{"name":"weights","rows":2,"columns":3,"values":[1,2,3,4,5,6]},{"name":"biases","rows":1,"columns":3,"values":[1,2,3]}]},` +
+			`{"type":"softmax"},` +
+			`{"type":"prelu","params":[{"name":"alphas","rows":1,"columns":2,"values":[0.1,0.1]}]}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nn, err := neuralnet.FromJSON(`{"version":2,"loss":{"name":"mse"},"optimizer":{"name":"sgd"},"layers":[` + tc.layers + `]}`)
+			if err == nil || !strings.Contains(err.Error(), "inputs") {
+				t.Errorf("expected err about the inputs, got %v", err)
+			}
+			if nn != nil {
+				t.Errorf("expected nn to be nil")
+			}
+		})
+	}
+}
+
+func TestToJSONWithCustomActivation(t *testing.T) {
+	for _, name := range []string{"custom", ""} {
+		nn, err := neuralnet.New(neuralnet.WithActivation(activation.Activation{
+			Name:       name,
+			Function:   activation.Sigmoid,
+			Derivative: activation.SigmoidPrime,
+		}))
+		if err != nil {
+			t.Errorf("expected err to be nil, got %v", err)
+		}
+
+		// functions given by the caller cannot be known by name, so saving
+		// fails instead of writing a file that cannot be loaded
+		if _, err := nn.ToJSON(); err == nil || !strings.Contains(err.Error(), "activation") {
+			t.Errorf("%q: expected err about the activation, got %v", name, err)
+		}
+		if err := nn.Save(filepath.Join(t.TempDir(), "model.json")); err == nil {
+			t.Errorf("%q: expected err to be not nil", name)
+		}
+	}
+}
+
+// unexportedOptimizer is an optimizer model files cannot describe.
+type unexportedOptimizer struct{}
+
+func (unexportedOptimizer) Update(learningRate float64, params, gradients []*matrix.Matrix) ([]*matrix.Matrix, error) {
+	return optimizer.NewSGD().Update(learningRate, params, gradients)
+}
+
+func TestToJSONWithUnexportedOptimizer(t *testing.T) {
+	nn, err := neuralnet.New(neuralnet.WithOptimizer(unexportedOptimizer{}))
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	if _, err := nn.ToJSON(); err == nil || !strings.Contains(err.Error(), "optimizer") {
+		t.Errorf("expected err about the optimizer, got %v", err)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:   2,
+		HiddenLayerSizes: []int{5},
+		OutputLayerSize:  1,
+		LearningRate:     0.01,
+		Activation:       activation.NewTanh(),
+	})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "model.json")
+
+	if err := nn.Save(path); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	loaded, err := neuralnet.Load(path)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	ensureSamePredictions(t, nn, loaded)
+}
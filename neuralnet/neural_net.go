@@ -1,176 +1,303 @@
 package neuralnet
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
+	"time"
 
+	"github.com/buarki/supervised-machine-learning/activation"
+	"github.com/buarki/supervised-machine-learning/initializer"
+	"github.com/buarki/supervised-machine-learning/loss"
 	"github.com/buarki/supervised-machine-learning/matrix"
+	"github.com/buarki/supervised-machine-learning/optimizer"
 )
 
-// Defining hyperparameters as inner constants
+// Defining the default topology as inner constants
 const (
-	inputLayerSize      = 2
-	outputLayerSize     = 1
-	hiddenLayerSize     = 3
-	amountOfInputParams = 3
+	inputLayerSize  = 2
+	outputLayerSize = 1
+	hiddenLayerSize = 3
+
+	defaultLearningRate = 0.01
 )
 
 // NeuralNet defines the data needed for the planned
-// neural network. It is supposed to have an input layer
-// with two inputs, a hidden layer with three neurons
-// and an output layer with one ouput.
+// neural network. It holds a stack of layers where the
+// first one receives the input and the last one gives
+// the prediction.
 type NeuralNet struct {
-	inputLayerSize       int     // The dimensions of input layer
-	outputLayerSize      int     // How many neurons are present on second layer
-	hiddenLayerSize      int     // The dimensions of output layer
-	amountOfInputParams  int     // The number of input samples. Needed for normalization
 	learningRate         float64 // Learning rate
 	regularizationFactor float64 // Regularization factor
 
-	w2 *matrix.Matrix // Matrix with weights of second layer
-	b2 *matrix.Matrix // Matrix with bias values of second layer
-	w3 *matrix.Matrix // Matrix with weights of third layer
-	b3 *matrix.Matrix // Matrix with bias values of third layer
-
-	activationFunction      func(v float64) float64 // Activation function to be used
-	activationFunctionPrime func(v float64) float64 // Prime of the activation function used
-}
-
-// New creates and returns a neural network. It requires as argument the learning rate,
-// regularization factor, activation function and the activation function prime.
-func New(learningRate, regularizationFactor float64, activationFunction, activationFunctionPrime func(v float64) float64) (*NeuralNet, error) {
-	// w2 is the second layer weights matrix. As it holds the weighs that will interact
-	// with X it needs to be (2x3)
-	w2, err := matrix.New(inputLayerSize, hiddenLayerSize, generateRandomValues(inputLayerSize*hiddenLayerSize))
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate w2 weights, got %v", err)
+	model     *Sequential         // Layers of the network, from the second to the output one
+	loss      loss.Loss           // Measures the prediction error
+	optimizer optimizer.Optimizer // Computes new params from their gradients during train
+	source    rand.Source         // Source of randomness of the network
+	random    *rand.Rand          // Draws values from source
+
+	workspace trainWorkspace // Matrices reused by every train step
+}
+
+// Config describes the neural network built by NewWithConfig.
+type Config struct {
+	InputLayerSize       int                   // How many features each sample has
+	HiddenLayerSizes     []int                 // How many neurons each hidden layer has
+	OutputLayerSize      int                   // How many values are predicted for each sample
+	LearningRate         float64               // Learning rate
+	RegularizationFactor float64               // Regularization factor
+	Activation           activation.Activation // Activation of the hidden layers
+
+	// OutputActivation is the activation of the output layer, for
+	// instance activation.NewIdentity() for unbounded regression.
+	// Activation is used when it is not set.
+	OutputActivation activation.Activation
+
+	// WeightInitializers gives the initializer of the weights of each
+	// layer, from the first hidden one to the output one. Missing or nil
+	// entries use the default of the layer activation, He for ReLU like
+	// ones, LeCun normal for SELU and Glorot uniform for the others.
+	WeightInitializers []initializer.Initializer
+
+	// WeightInitializer initializes the weights of the layers that have
+	// no entry on WeightInitializers.
+	WeightInitializer initializer.Initializer
+
+	// BiasInitializer initializes the biases of every layer. Zeros are
+	// used when nil.
+	BiasInitializer initializer.Initializer
+
+	// SoftmaxOutput appends a Softmax layer after the output one, so the
+	// network predicts the probability of each of OutputLayerSize classes.
+	// The output layer then uses OutputActivation only when it is set,
+	// identity otherwise, and Loss defaults to categorical cross entropy.
+	SoftmaxOutput bool
+
+	// Layers, when given, is used as the network and the
+	// layer sizes and activations above are ignored.
+	Layers []Layer
+
+	// Loss used to evaluate predictions and to start the backward
+	// process. Half the squared error (loss.MSE) is used when nil.
+	Loss loss.Loss
+
+	// Optimizer used to update params during train. Plain
+	// gradient descent (optimizer.SGD) is used when nil.
+	Optimizer optimizer.Optimizer
+
+	// Source of randomness for the initial params and the train. A
+	// source seeded with the current time is used when nil, so give
+	// NewSource(seed) to get the same results on every run.
+	Source rand.Source
+}
+
+// NewWithConfig creates and returns a neural network whose layers
+// are taken from the given config.
+func NewWithConfig(config Config) (*NeuralNet, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
 	}
-	// b2 is the second layer bias. As we sum it with v2 it must have the same dimension (3x3)
-	b2, err := matrix.New(hiddenLayerSize, hiddenLayerSize, generateRandomValues(hiddenLayerSize*hiddenLayerSize))
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate b2 weights, got %v", err)
+	source := config.Source
+	if source == nil {
+		source = NewSource(time.Now().UnixNano())
 	}
-	// w3 is the second layer weights matrix. As it holds the weighs that will interact
-	// with Y^2 it needs to be (3x1)
-	w3, err := matrix.New(hiddenLayerSize, outputLayerSize, generateRandomValues(hiddenLayerSize*outputLayerSize))
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate b2 weights, got %v", err)
+	random := rand.New(source)
+	layers := config.Layers
+	if len(layers) == 0 {
+		var err error
+		layers, err = denseLayersFor(config, random)
+		if err != nil {
+			return nil, err
+		}
 	}
-	// b3 is the third layer bias. As we sum it with v3 it must have the same dimension (3x1)
-	b3, err := matrix.New(hiddenLayerSize, outputLayerSize, generateRandomValues(hiddenLayerSize*outputLayerSize))
+	model, err := NewSequential(layers...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate b2 weights, got %v", err)
+		return nil, fmt.Errorf("failed to create model, got %v", err)
+	}
+	l := config.Loss
+	if l == nil {
+		l = loss.NewMSE()
+		if config.SoftmaxOutput {
+			l = loss.NewCategoricalCrossEntropy()
+		}
+	}
+	opt := config.Optimizer
+	if opt == nil {
+		opt = optimizer.NewSGD()
 	}
 	return &NeuralNet{
-		learningRate:            learningRate,
-		regularizationFactor:    regularizationFactor,
-		inputLayerSize:          inputLayerSize,
-		outputLayerSize:         outputLayerSize,
-		hiddenLayerSize:         hiddenLayerSize,
-		amountOfInputParams:     amountOfInputParams,
-		w2:                      w2,
-		b2:                      b2,
-		w3:                      w3,
-		b3:                      b3,
-		activationFunction:      activationFunction,
-		activationFunctionPrime: activationFunctionPrime,
+		learningRate:         config.LearningRate,
+		regularizationFactor: config.RegularizationFactor,
+		model:                model,
+		loss:                 l,
+		optimizer:            opt,
+		source:               source,
+		random:               random,
 	}, nil
 }
 
-func (nn *NeuralNet) InputLayerSize() int {
-	return nn.inputLayerSize
-}
-
-func (nn *NeuralNet) HiddenLayerSize() int {
-	return nn.hiddenLayerSize
+// sizes returns the size of each layer, from the input one to the output one.
+func (config Config) sizes() []int {
+	sizes := append([]int{config.InputLayerSize}, config.HiddenLayerSizes...)
+	return append(sizes, config.OutputLayerSize)
 }
 
-func (nn *NeuralNet) OutputLayerSize() int {
-	return nn.outputLayerSize
+// weightsInitializer returns the initializer of the weights of the
+// layer at index, starting at 0 for the first hidden one.
+func (config Config) weightsInitializer(index int) initializer.Initializer {
+	if index < len(config.WeightInitializers) && config.WeightInitializers[index] != nil {
+		return config.WeightInitializers[index]
+	}
+	return config.WeightInitializer
 }
 
-// AdjustWeights changes w2 and w3 matrix. If given matrices
-// have invalid shape it will error.
-func (nn *NeuralNet) AdjustWeights(w2, w3 *matrix.Matrix) error {
-	if w2 == nil {
-		return fmt.Errorf("w2 cannot be nil")
+func denseLayersFor(config Config, random *rand.Rand) ([]Layer, error) {
+	sizes := config.sizes()
+	outputActivation := config.OutputActivation
+	if outputActivation.Function == nil && outputActivation.Derivative == nil {
+		outputActivation = config.Activation
+		if config.SoftmaxOutput {
+			outputActivation = activation.NewIdentity()
+		}
 	}
-	if w3 == nil {
-		return fmt.Errorf("w3 cannot be nil")
+	layers := make([]Layer, 0, len(sizes)-1)
+	for i := 1; i < len(sizes); i++ {
+		a := config.Activation
+		if i == len(sizes)-1 {
+			a = outputActivation
+		}
+		// each dense layer has weights (previous size x size) and biases (1 x size)
+		layer, err := NewDenseWithInitializers(sizes[i-1], sizes[i], a, config.weightsInitializer(i-1), config.BiasInitializer, random)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create layer %d, got %v", i+1, err)
+		}
+		layers = append(layers, layer)
 	}
-	if nn.w2.Rows != w2.Rows || nn.w2.Columns != w2.Columns {
-		return fmt.Errorf("given w2 has different shape, expected (%dx%d), received (%dx%d)", nn.w2.Rows, nn.w2.Columns, w2.Rows, w2.Columns)
+	if config.SoftmaxOutput {
+		layers = append(layers, NewSoftmax())
 	}
-	if nn.w3.Rows != w3.Rows || nn.w3.Columns != w3.Columns {
-		return fmt.Errorf("given w3 has different shape, expected (%dx%d), received (%dx%d)", nn.w3.Rows, nn.w3.Columns, w3.Rows, w3.Columns)
+	return layers, nil
+}
+
+// Layers returns the layers of the network, from the
+// second (first hidden) to the output one.
+func (nn *NeuralNet) Layers() []Layer {
+	return nn.model.Layers()
+}
+
+// Weights returns the weights of each layer, so the first
+// element is W2, the second is W3 and so on.
+func (nn *NeuralNet) Weights() []*matrix.Matrix {
+	return values(nn.parameters(ParameterWeights))
+}
+
+// Biases returns the biases of each layer, so the first
+// element is B2, the second is B3 and so on.
+func (nn *NeuralNet) Biases() []*matrix.Matrix {
+	return values(nn.parameters(ParameterBiases))
+}
+
+// Params returns the trainable params of every layer, which
+// besides weights and biases may hold, for instance, PReLU alphas.
+func (nn *NeuralNet) Params() []*Parameter {
+	return nn.model.Params()
+}
+
+// AdjustWeights changes the weights of each layer. If the amount
+// of given matrices or their shapes are invalid it will error.
+func (nn *NeuralNet) AdjustWeights(weights ...*matrix.Matrix) error {
+	return adjust(nn.parameters(ParameterWeights), weights)
+}
+
+// AdjustBiases changes the biases of each layer. If the amount
+// of given matrices or their shapes are invalid it will error.
+func (nn *NeuralNet) AdjustBiases(biases ...*matrix.Matrix) error {
+	return adjust(nn.parameters(ParameterBiases), biases)
+}
+
+func (nn *NeuralNet) parameters(name string) []*Parameter {
+	var params []*Parameter
+	for _, param := range nn.model.Params() {
+		if param.Name == name {
+			params = append(params, param)
+		}
 	}
-	nn.w2 = w2
-	nn.w3 = w3
-	return nil
+	return params
 }
 
-// AdjustBiases changes b2 and b3 matrix. If given matrices
-// have invalid shape it will error.
-func (nn *NeuralNet) AdjustBiases(b2, b3 *matrix.Matrix) error {
-	if b2 == nil {
-		return fmt.Errorf("b2 cannot be nil")
+func values(params []*Parameter) []*matrix.Matrix {
+	matrices := make([]*matrix.Matrix, len(params))
+	for i, param := range params {
+		matrices[i] = param.Value
 	}
-	if b3 == nil {
-		return fmt.Errorf("b3 cannot be nil")
+	return matrices
+}
+
+// adjust sets a copy of each new value on its param, as params are
+// changed in place during train.
+func adjust(params []*Parameter, newValues []*matrix.Matrix) error {
+	if len(params) != len(newValues) {
+		return fmt.Errorf("expected %d matrices, received %d", len(params), len(newValues))
 	}
-	if nn.b2.Rows != b2.Rows || nn.b2.Columns != b2.Columns {
-		return fmt.Errorf("given b2 has different shape, expected (%dx%d), received (%dx%d)", nn.b2.Rows, nn.b2.Columns, b2.Rows, b2.Columns)
+	for i, newValue := range newValues {
+		if newValue == nil {
+			return fmt.Errorf("%s %d cannot be nil", params[i].Name, i)
+		}
+		current := params[i].Value
+		if current.Rows != newValue.Rows || current.Columns != newValue.Columns {
+			return fmt.Errorf("given %s %d has different shape, expected (%dx%d), received (%dx%d)", params[i].Name, i, current.Rows, current.Columns, newValue.Rows, newValue.Columns)
+		}
 	}
-	if nn.b3.Rows != b3.Rows || nn.b3.Columns != b3.Columns {
-		return fmt.Errorf("given b3 has different shape, expected (%dx%d), received (%dx%d)", nn.b3.Rows, nn.b3.Columns, b3.Rows, b3.Columns)
+	for i, newValue := range newValues {
+		params[i].Value = newValue.Copy()
 	}
-	nn.b2 = b2
-	nn.b3 = b3
 	return nil
 }
 
-func (nn *NeuralNet) RegularizationFactor() float64 {
-	return nn.regularizationFactor
-}
-
-func (nn *NeuralNet) W2() *matrix.Matrix {
-	return nn.w2
+func (nn *NeuralNet) LearningRate() float64 {
+	return nn.learningRate
 }
 
-func (nn *NeuralNet) W3() *matrix.Matrix {
-	return nn.w3
+func (nn *NeuralNet) Loss() loss.Loss {
+	return nn.loss
 }
 
-func (nn *NeuralNet) B2() *matrix.Matrix {
-	return nn.b2
+func (nn *NeuralNet) Optimizer() optimizer.Optimizer {
+	return nn.optimizer
 }
 
-func (nn *NeuralNet) B3() *matrix.Matrix {
-	return nn.b3
+func (nn *NeuralNet) RegularizationFactor() float64 {
+	return nn.regularizationFactor
 }
 
 // Predict executes the forward process and returns the
-// predicted values.
+// predicted values. It doesn't change the network, so it
+// can run concurrently with other predictions.
 func (nn *NeuralNet) PredictBasedOn(X *matrix.Matrix) (*matrix.Matrix, error) {
-	augmentedResult, err := nn.PredictForAnalysisBasedOn(X)
+	if X == nil {
+		return nil, errors.New("param x cannot be nil")
+	}
+	prediction, err := nn.model.predict(X)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to predict, got %v", err)
 	}
-	return augmentedResult.Y3, nil
+	return prediction, nil
 }
 
 // ForwardResult is used in the training process to carry computed matrices.
+// Each slice has one element per layer, so index 0 refers to the second
+// layer (V2, Y2, W2 and B2), index 1 to the third one and so on.
 type ForwardResult struct {
-	W2 *matrix.Matrix
-	B2 *matrix.Matrix
-	W3 *matrix.Matrix
-	B3 *matrix.Matrix
-	V2 *matrix.Matrix
-	Y2 *matrix.Matrix
-	V3 *matrix.Matrix
-	Y3 *matrix.Matrix
-	X  *matrix.Matrix
+	Weights []*matrix.Matrix
+	Biases  []*matrix.Matrix
+	V       []*matrix.Matrix // Values of each layer before the activation, the Y ones for layers without it
+	Y       []*matrix.Matrix // Values of each layer after the activation
+	X       *matrix.Matrix
+}
+
+// Prediction returns the output of the last layer.
+func (f *ForwardResult) Prediction() *matrix.Matrix {
+	return f.Y[len(f.Y)-1]
 }
 
 type EvaluationResult struct {
@@ -183,7 +310,7 @@ func (nn *NeuralNet) Evaluate(expected, predicted *matrix.Matrix) (*EvaluationRe
 	if err != nil {
 		return nil, err
 	}
-	errorCost, err := nn.computeErrorCost(predictionErrorMatrix)
+	errorCost, err := nn.computeErrorCost(nn.Params(), expected, predicted)
 	if err != nil {
 		return nil, err
 	}
@@ -194,265 +321,171 @@ func (nn *NeuralNet) Evaluate(expected, predicted *matrix.Matrix) (*EvaluationRe
 }
 
 // PredictForAnalysisBasedOn executes the forward process and returns the computed
-// matrices related to the backward process.
+// matrices related to the backward process. The layers cache what ComputeGradients
+// needs, so unlike PredictBasedOn it must not run concurrently.
 func (nn *NeuralNet) PredictForAnalysisBasedOn(X *matrix.Matrix) (*ForwardResult, error) {
 	if X == nil {
 		return nil, errors.New("param x cannot be nil")
 	}
-
-	v2, err := X.DotProductWith(nn.w2)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute v2, got %v", err)
-	}
-	v2PlusB2, err := v2.SumWith(nn.b2)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute x*w2 + b2, got %v", err)
-	}
-	y2, err := v2PlusB2.ApplyElementWise(nn.activationFunction)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute y2, got %v", err)
-	}
-
-	v3, err := y2.DotProductWith(nn.w3)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute v3, got %v", err)
-	}
-	v3PlusB3, err := v3.SumWith(nn.b3)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute y2*w3 + B3, got %v", err)
-	}
-	y3, err := v3PlusB3.ApplyElementWise(nn.activationFunction)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute y3, got %v", err)
-	}
-
-	return &ForwardResult{
-		V2: v2PlusB2,
-		Y2: y2,
-		V3: v3PlusB3,
-		Y3: y3,
-		W2: nn.w2,
-		W3: nn.w3,
-		B2: nn.b2,
-		B3: nn.b3,
-		X:  X,
-	}, nil
-}
-
+	layers := nn.model.Layers()
+	result := &ForwardResult{
+		Weights: nn.Weights(),
+		Biases:  nn.Biases(),
+		V:       make([]*matrix.Matrix, len(layers)),
+		Y:       make([]*matrix.Matrix, len(layers)),
+		X:       X,
+	}
+	input := X
+	for i, layer := range layers {
+		y, err := layer.Forward(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute y%d, got %v", i+2, err)
+		}
+		// layers reuse their matrices on the next forward process
+		result.Y[i] = y.Copy()
+		if dense, ok := layer.(*Dense); ok {
+			result.V[i] = dense.PreActivation().Copy()
+		} else {
+			result.V[i] = result.Y[i]
+		}
+		input = y
+	}
+	return result, nil
+}
+
+// GradientComponents holds the gradient of each layer parameters, where
+// index 0 refers to the second layer (dEdW2 and dEdB2) and so on.
 type GradientComponents struct {
-	DEdW3 *matrix.Matrix
-	DEdW2 *matrix.Matrix
-	DEdB2 *matrix.Matrix
-	DEdB3 *matrix.Matrix
+	DEdW      []*matrix.Matrix
+	DEdB      []*matrix.Matrix
+	DEdParams []*matrix.Matrix // Gradients of all params, in the order of NeuralNet.Params
 }
 
 // ComputeGradients computes the gradient descent components
-// of w2,w3,b2 and b3.
+// of the weights and biases of every layer.
 func (nn *NeuralNet) ComputeGradients(expected, errorMatrix *matrix.Matrix, forwardResult *ForwardResult) (*GradientComponents, error) {
 	res, err := nn.ComputeGradientsForAnalysis(expected, errorMatrix, forwardResult)
 	if err != nil {
 		return nil, err
 	}
 	return &GradientComponents{
-		DEdW3: res.DEdW3,
-		DEdW2: res.DEdW2,
-		DEdB3: res.DEdB3,
-		DEdB2: res.DEdB2,
+		DEdW:      res.DEdW,
+		DEdB:      res.DEdB,
+		DEdParams: res.DEdParams,
 	}, nil
 }
 
 type AugmentedGradientComponents struct {
-	DEdW3  *matrix.Matrix
-	DEdW2  *matrix.Matrix
-	DEdB2  *matrix.Matrix
-	DEdB3  *matrix.Matrix
-	Delta3 *matrix.Matrix
-	Delta2 *matrix.Matrix
-	W2     *matrix.Matrix
-	W3     *matrix.Matrix
-	X      *matrix.Matrix
-	B2     *matrix.Matrix
-	B3     *matrix.Matrix
-}
-
+	DEdW      []*matrix.Matrix
+	DEdB      []*matrix.Matrix
+	DEdParams []*matrix.Matrix
+	Delta     []*matrix.Matrix
+	Weights   []*matrix.Matrix
+	Biases    []*matrix.Matrix
+	X         *matrix.Matrix
+}
+
+// ComputeGradientsForAnalysis runs the backward process through all layers. It relies
+// on the values cached by the layers, so it must follow the forward process that
+// produced forwardResult.
 func (nn *NeuralNet) ComputeGradientsForAnalysis(expected, errorMatrix *matrix.Matrix, forwardResult *ForwardResult) (*AugmentedGradientComponents, error) {
-	delta3, dEdW3, dEdB3, err := nn.computeLayer3Params(expected, forwardResult)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute layer 3 params, got %v", err)
+	if forwardResult == nil {
+		return nil, errors.New("forward result cannot be nil")
 	}
-	delta2, dEdW2, dEdB2, err := nn.computeLayer2Params(delta3, expected, forwardResult)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute layer 2 params, got %v", err)
+	if err := nn.backward(expected, forwardResult.Prediction()); err != nil {
+		return nil, err
+	}
+	// gradients are averaged over the samples actually given
+	amountOfInputParams := forwardResult.X.Rows
+	var dEdParams, dEdW, dEdB []*matrix.Matrix
+	for i, param := range nn.model.Params() {
+		gradient, err := matrix.NewFromSlice(param.Value.Rows, param.Value.Columns, make([]float64, param.Value.Rows*param.Value.Columns))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gradient of param %d (%s), got %v", i, param.Name, err)
+		}
+		if err := nn.computeGradientInto(gradient, param, amountOfInputParams); err != nil {
+			return nil, fmt.Errorf("failed to compute gradient of param %d (%s), got %v", i, param.Name, err)
+		}
+		dEdParams = append(dEdParams, gradient)
+		switch param.Name {
+		case ParameterWeights:
+			dEdW = append(dEdW, gradient)
+		case ParameterBiases:
+			dEdB = append(dEdB, gradient)
+		}
+	}
+	var deltas []*matrix.Matrix
+	for _, layer := range nn.model.Layers() {
+		if dense, ok := layer.(*Dense); ok {
+			deltas = append(deltas, dense.Delta())
+		}
 	}
 	return &AugmentedGradientComponents{
-		DEdW3:  dEdW3,
-		DEdW2:  dEdW2,
-		DEdB3:  dEdB3,
-		DEdB2:  dEdB2,
-		Delta3: delta3,
-		Delta2: delta2,
-		W2:     nn.w2,
-		W3:     nn.w3,
-		X:      forwardResult.X,
+		DEdW:      dEdW,
+		DEdB:      dEdB,
+		DEdParams: dEdParams,
+		Delta:     deltas,
+		Weights:   nn.Weights(),
+		Biases:    nn.Biases(),
+		X:         forwardResult.X,
 	}, nil
 }
 
-func (nn *NeuralNet) computeLayer3Params(expected *matrix.Matrix, forwardResult *ForwardResult) (*matrix.Matrix, *matrix.Matrix, *matrix.Matrix, error) {
-	errorMatrix, err := nn.computeExpectedMinusPredicted(expected, forwardResult.Y3)
+// backward fills the gradients of every param. When the network ends with
+// a Softmax layer trained with categorical cross entropy, dE/dX of softmax
+// simplifies to predicted - expected, so the backward process starts from
+// the layer before it without going through the softmax jacobian.
+func (nn *NeuralNet) backward(expected, predicted *matrix.Matrix) error {
+	layers := nn.model.Layers()
+	_, endsWithSoftmax := layers[len(layers)-1].(*Softmax)
+	_, isCategoricalCrossEntropy := nn.loss.(*loss.CategoricalCrossEntropy)
+	outputGradient, err := nn.workspace.outputGradient.shaped(predicted.Rows, predicted.Columns)
 	if err != nil {
-		return nil, nil, nil, err
+		return fmt.Errorf("failed to create output gradient, got %v", err)
 	}
-	delta3, err := nn.computeDelta3(errorMatrix, forwardResult.V3)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to compute delta3, got %v", err)
-	}
-	dEdW3, err := nn.computeDdEdW3(delta3, forwardResult.Y2, forwardResult.X)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("faile to compute dEdW3, got %v", err)
-	}
-	dEdB3, err := nn.computeDEdB3(delta3, forwardResult.X)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to compute dEdB3, got %v", err)
-	}
-	return delta3, dEdW3, dEdB3, nil
-}
-
-func (nn *NeuralNet) computeDelta3(errorMatrix, V3 *matrix.Matrix) (*matrix.Matrix, error) {
-	sigmoidPrimeOfV3, err := V3.ApplyElementWise(nn.activationFunctionPrime)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute sigmoid prime of v3, got %v", err)
-	}
-	hadamardOfErrorMatrixAndSigmoidPrime, err := errorMatrix.HadamardProductWith(sigmoidPrimeOfV3)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute hadamard product of (expected - predicted) * sigmoid prime of v3, got %v", err)
-	}
-	delta3, err := hadamardOfErrorMatrixAndSigmoidPrime.ApplyElementWise(func(value float64) float64 {
-		return -value
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute delta3, got %v", err)
-	}
-	return delta3, nil
-}
-
-func (nn *NeuralNet) computeDdEdW3(delta3, Y2, X *matrix.Matrix) (*matrix.Matrix, error) {
-	dEdW3, err := Y2.T().DotProductWith(delta3)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute dEdW3, got %v", err)
-	}
-	dEdW3Normalized, err := dEdW3.ApplyElementWise(func(value float64) float64 {
-		return value / float64(nn.amountOfInputParams)
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to normalize dEdW3, got %v", err)
-	}
-	dEdW3Penalty, err := nn.w3.ApplyElementWise(func(value float64) float64 {
-		return value * nn.regularizationFactor
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute penalty of dEdW3, got %v", err)
-	}
-	dEdW3Regularized, err := dEdW3Normalized.SumWith(dEdW3Penalty)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute penalty of dEdW3 + penalty, got %v", err)
-	}
-	return dEdW3Regularized, nil
-}
-
-func (nn *NeuralNet) computeDEdB3(delta3, X *matrix.Matrix) (*matrix.Matrix, error) {
-	delta3Normalized, err := delta3.ApplyElementWise(func(value float64) float64 {
-		return value / float64(nn.amountOfInputParams)
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to normalize delta3, got %v", err)
-	}
-	delta3Penalty, err := nn.b3.ApplyElementWise(func(value float64) float64 {
-		return value * nn.regularizationFactor
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute penalty for delta3, got %v", err)
-	}
-	dEdB3, err := delta3Normalized.SumWith(delta3Penalty)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute normalized delta3 + penalty, got %v", err)
-	}
-	return dEdB3, nil
-}
-
-func (nn *NeuralNet) computeLayer2Params(delta3, expected *matrix.Matrix, forwardResult *ForwardResult) (*matrix.Matrix, *matrix.Matrix, *matrix.Matrix, error) {
-	delta2, err := nn.computeDelta2(delta3, forwardResult.V2)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to compute delta2, got %v", err)
-	}
-	dEdW2, err := nn.computeDEdW2(delta2, forwardResult.X)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to compute penalty of dEdW2 + penalty, got %v", err)
-	}
-	dEdB2, err := nn.computeDEdB2(delta2)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to compute dEdB2, got %v", err)
+	if endsWithSoftmax && isCategoricalCrossEntropy {
+		softmaxInputGradient := outputGradient
+		if err := matrix.SubInto(softmaxInputGradient, predicted, expected); err != nil {
+			return fmt.Errorf("failed to compute predicted - expected, got %v", err)
+		}
+		if _, err := nn.model.backwardFrom(len(layers)-2, softmaxInputGradient); err != nil {
+			return fmt.Errorf("failed to run backward process, got %v", err)
+		}
+		return nil
 	}
-	return delta2, dEdW2, dEdB2, nil
-}
-
-func (nn *NeuralNet) computeDelta2(delta3, V2 *matrix.Matrix) (*matrix.Matrix, error) {
-	delta3TimesW3T, err := delta3.DotProductWith(nn.w3.T())
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute delta3 dot with W3T, got %v", err)
+	// dE/dY of the output layer
+	if gradientWriter, ok := nn.loss.(loss.GradientWriter); ok {
+		err = gradientWriter.GradientInto(outputGradient, expected, predicted)
+	} else {
+		outputGradient, err = nn.loss.Gradient(expected, predicted)
 	}
-	sigmoidPrimeOfV2, err := V2.ApplyElementWise(nn.activationFunctionPrime)
 	if err != nil {
-		return nil, fmt.Errorf("failed to compute sigmoid prime of v2, got %v", err)
+		return fmt.Errorf("failed to compute gradient of %s loss, got %v", nn.loss.Name(), err)
 	}
-	delta2, err := delta3TimesW3T.HadamardProductWith(sigmoidPrimeOfV2)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute delta3*W3T*sigmoidPrime of v2, got %v", err)
+	if _, err := nn.model.Backward(outputGradient); err != nil {
+		return fmt.Errorf("failed to run backward process, got %v", err)
 	}
-	return delta2, nil
+	return nil
 }
 
-func (nn *NeuralNet) computeDEdW2(delta2, X *matrix.Matrix) (*matrix.Matrix, error) {
-	dEdW2, err := X.T().DotProductWith(delta2)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute dEdW2, got %v", err)
-	}
-	dEdW2Normalized, err := dEdW2.ApplyElementWise(func(value float64) float64 {
-		return value / float64(nn.amountOfInputParams)
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to normalize dEdW2, got %v", err)
-	}
-	dEdW2Penalty, err := nn.w2.ApplyElementWise(func(value float64) float64 {
-		return value * nn.regularizationFactor
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute penalty of dEdW2, got %v", err)
-	}
-	dEdW2Regularized, err := dEdW2Normalized.SumWith(dEdW2Penalty)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute penalty of dEdW2 + penalty, got %v", err)
+// computeGradientInto writes on dst the gradient of param averaged over
+// the samples plus regularizationFactor*param for weights and biases.
+func (nn *NeuralNet) computeGradientInto(dst *matrix.Matrix, param *Parameter, amountOfInputParams int) error {
+	if param.Gradient == nil {
+		return errors.New("gradient was not computed")
 	}
-	return dEdW2Regularized, nil
-}
-
-func (nn *NeuralNet) computeDEdB2(delta2 *matrix.Matrix) (*matrix.Matrix, error) {
-	delta2Normalized, err := delta2.ApplyElementWise(func(value float64) float64 {
-		return value / float64(nn.amountOfInputParams)
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to normalize delta2, got %v", err)
+	if err := matrix.ApplyInto(dst, param.Gradient, func(value float64) float64 {
+		return value / float64(amountOfInputParams)
+	}); err != nil {
+		return fmt.Errorf("failed to normalize gradient, got %v", err)
 	}
-	delta2Penalty, err := nn.b2.ApplyElementWise(func(value float64) float64 {
-		return value * nn.regularizationFactor
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute penalty for delta2, got %v", err)
+	if param.Name != ParameterWeights && param.Name != ParameterBiases {
+		return nil
 	}
-	dEdB2, err := delta2Normalized.SumWith(delta2Penalty)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute normalized delta2 + penalty, got %v", err)
+	if err := dst.AddScaledInPlace(param.Value, nn.regularizationFactor); err != nil {
+		return fmt.Errorf("failed to compute normalized gradient + penalty, got %v", err)
 	}
-	return dEdB2, nil
+	return nil
 }
 
 type PredictionWithError struct {
@@ -460,21 +493,25 @@ type PredictionWithError struct {
 	Error      float64
 }
 
-func (nn *NeuralNet) computeErrorCost(errorMatrix *matrix.Matrix) (float64, error) {
-	w2Hadamard, err := nn.W2().HadamardProductWith(nn.W2())
-	if err != nil {
-		return 0, fmt.Errorf("failed to compute hadamard product w2 * w2, got %v", err)
-	}
-	w3Hadamard, err := nn.W3().HadamardProductWith(nn.W3())
-	if err != nil {
-		return 0, fmt.Errorf("failed to compute hadamard product w3 * w3, got %v", err)
+// computeErrorCost returns the loss plus the penalty of the weights among params.
+func (nn *NeuralNet) computeErrorCost(params []*Parameter, expected, predicted *matrix.Matrix) (float64, error) {
+	sumOfSquaredWeights := 0.0
+	for _, param := range params {
+		if param.Name != ParameterWeights {
+			continue
+		}
+		sumOfSquares := 0.0
+		for _, value := range param.Value.FlattenedElements() {
+			sumOfSquares += value * value
+		}
+		sumOfSquaredWeights += sumOfSquares
 	}
-	penalty := (nn.RegularizationFactor() / 2.0) * (w2Hadamard.SumOfAllElements() + w3Hadamard.SumOfAllElements())
-	errorMatrixHadamardProduct, err := errorMatrix.HadamardProductWith(errorMatrix)
+	penalty := (nn.RegularizationFactor() / 2.0) * sumOfSquaredWeights
+	lossValue, err := nn.loss.Value(expected, predicted)
 	if err != nil {
-		return 0, fmt.Errorf("failed to compute hadamard product (expected - predicted)*(expected - predicted), got %v", err)
+		return 0, fmt.Errorf("failed to compute %s loss, got %v", nn.loss.Name(), err)
 	}
-	return (0.5 * errorMatrixHadamardProduct.SumOfAllElements() / float64(nn.amountOfInputParams)) + penalty, nil
+	return lossValue + penalty, nil
 }
 
 func (nn *NeuralNet) computeExpectedMinusPredicted(expected, predicted *matrix.Matrix) (*matrix.Matrix, error) {
@@ -484,27 +521,3 @@ func (nn *NeuralNet) computeExpectedMinusPredicted(expected, predicted *matrix.M
 	}
 	return predictionError, nil
 }
-
-// ToJSON can be used to export the neural net state.
-func (nn *NeuralNet) ToJSON() (string, error) {
-	neuralNetState := struct {
-		LearningRate         float64   `json:"learningRate"`
-		RegularizationFactor float64   `json:"regularizationFactor"`
-		W2                   []float64 `json:"w2"`
-		W3                   []float64 `json:"w3"`
-		B2                   []float64 `json:"b2"`
-		B3                   []float64 `json:"b3"`
-	}{
-		W2:                   nn.w2.FlattenedElements(),
-		W3:                   nn.w3.FlattenedElements(),
-		B2:                   nn.b2.FlattenedElements(),
-		B3:                   nn.b3.FlattenedElements(),
-		LearningRate:         nn.learningRate,
-		RegularizationFactor: nn.regularizationFactor,
-	}
-	b, err := json.Marshal(neuralNetState)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate neural net JSON, got %v", err)
-	}
-	return string(b), nil
-}
@@ -2,9 +2,14 @@ package neuralnet_test
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
+	"sync"
 	"testing"
 
 	"github.com/buarki/supervised-machine-learning/activation"
+	"github.com/buarki/supervised-machine-learning/initializer"
+	"github.com/buarki/supervised-machine-learning/loss"
 	"github.com/buarki/supervised-machine-learning/matrix"
 	"github.com/buarki/supervised-machine-learning/neuralnet"
 	"github.com/buarki/supervised-machine-learning/sample"
@@ -21,7 +26,7 @@ func TestExecutePredictBasedOn(t *testing.T) {
 		t.Errorf("expected error to be nil, got %v", err)
 	}
 	X := inputOutput.Input
-	nn, err := neuralnet.New(0.001, 0.0001, activation.Sigmoid, activation.SigmoidPrime)
+	nn, err := neuralnet.New(neuralnet.WithLearningRate(0.001), neuralnet.WithRegularizer(0.0001))
 	if err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
 	}
@@ -38,33 +43,33 @@ func TestExecutePredictBasedOn(t *testing.T) {
 	knownW2 := weightsSample.W2
 	knownW3 := weightsSample.W3
 	expectedV2, err := matrix.New(3, 3, []float64{
-		0.792667185, 1.76936391, -0.291706973,
-		-0.917605334, -0.0777543792, 0.654353841,
-		-0.900988121, -0.0495140693, -0.970005329,
+		0.7926671852528919, 1.7693639077771635, -0.2917069728333399,
+		0.8349676652442355, 1.281607864867212, -1.1588807717869192,
+		0.8446150695955124, 1.8578623014741438, -1.4768834364160974,
 	})
 	if err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
 	}
 	expectedY2, err := matrix.New(3, 3, []float64{
-		0.68840374, 0.85437855, 0.42758602,
-		0.28544608, 0.48057119, 0.65799092,
-		0.28884748, 0.48762401, 0.27487944,
+		0.688403739871207, 0.8543785489504127, 0.4275860233093505,
+		0.6974042907859404, 0.7827233462973376, 0.2388707142369233,
+		0.6994363118203425, 0.8650475872548848, 0.18589861938433044,
 	})
 	if err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
 	}
 	expectedV3, err := matrix.New(3, 1, []float64{
-		-0.71246648,
-		0.25750816,
-		-1.63416412,
+		-0.7124664779537394,
+		-0.5160763637527284,
+		-0.5358024203486496,
 	})
 	if err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
 	}
 	expectedY3, err := matrix.New(3, 1, []float64{
-		0.32905407,
-		0.56402364,
-		0.16326072,
+		0.3290540679649881,
+		0.3737701678322917,
+		0.36916458540712493,
 	})
 	if err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
@@ -82,14 +87,14 @@ func TestExecutePredictBasedOn(t *testing.T) {
 		t.Errorf("expected err to be nil, got %v", err)
 	}
 
-	ensureMatricesAreEqual(t, prediction.W2, knownW2)
-	ensureMatricesAreEqual(t, prediction.W3, knownW3)
-	ensureMatricesAreEqual(t, prediction.B2, knownB2)
-	ensureMatricesAreEqual(t, prediction.B3, knownB3)
-	ensureMatricesMatch(t, prediction.V2, expectedV2, fmt.Sprintf("received V2 does not match with expected. Received V2:\n%s\nExpected V2:\n%s\n", prediction.V2.ToString(), expectedV2.ToString()))
-	ensureMatricesMatch(t, prediction.Y2, expectedY2, fmt.Sprintf("received Y2 does not match with expected. Received Y2:\n%s\nExpected Y2:\n%s\n", prediction.Y2.ToString(), expectedY2.ToString()))
-	ensureMatricesMatch(t, prediction.V3, expectedV3, fmt.Sprintf("received V3 does not match with expected. Received V3:\n%s\nExpected V3:\n%s\n", prediction.V3.ToString(), expectedV3.ToString()))
-	ensureMatricesMatch(t, prediction.Y3, expectedY3, fmt.Sprintf("received Y3 does not match with expected. Received Y3:\n%s\nExpected Y3:\n%s\n", prediction.Y3.ToString(), expectedY3.ToString()))
+	ensureMatricesAreEqual(t, prediction.Weights[0], knownW2)
+	ensureMatricesAreEqual(t, prediction.Weights[1], knownW3)
+	ensureMatricesAreEqual(t, prediction.Biases[0], knownB2)
+	ensureMatricesAreEqual(t, prediction.Biases[1], knownB3)
+	ensureMatricesMatch(t, prediction.V[0], expectedV2, fmt.Sprintf("received V2 does not match with expected. Received V2:\n%s\nExpected V2:\n%s\n", prediction.V[0].ToString(), expectedV2.ToString()))
+	ensureMatricesMatch(t, prediction.Y[0], expectedY2, fmt.Sprintf("received Y2 does not match with expected. Received Y2:\n%s\nExpected Y2:\n%s\n", prediction.Y[0].ToString(), expectedY2.ToString()))
+	ensureMatricesMatch(t, prediction.V[1], expectedV3, fmt.Sprintf("received V3 does not match with expected. Received V3:\n%s\nExpected V3:\n%s\n", prediction.V[1].ToString(), expectedV3.ToString()))
+	ensureMatricesMatch(t, prediction.Y[1], expectedY3, fmt.Sprintf("received Y3 does not match with expected. Received Y3:\n%s\nExpected Y3:\n%s\n", prediction.Y[1].ToString(), expectedY3.ToString()))
 }
 
 func TestAdjustWeightsWithInvalidW2(t *testing.T) {
@@ -97,11 +102,11 @@ func TestAdjustWeightsWithInvalidW2(t *testing.T) {
 	if err != nil {
 		t.Errorf("failed to create wrong w2, got %v", err)
 	}
-	nn, err := neuralnet.New(0.001, 0.0001, activation.Sigmoid, activation.SigmoidPrime)
+	nn, err := neuralnet.New(neuralnet.WithLearningRate(0.001), neuralnet.WithRegularizer(0.0001))
 	if err != nil {
 		t.Errorf("failed to create nn, got %v", err)
 	}
-	if err := nn.AdjustWeights(w2MatrixWithWrongDimensions, nn.W3()); err == nil {
+	if err := nn.AdjustWeights(w2MatrixWithWrongDimensions, nn.Weights()[1]); err == nil {
 		t.Errorf("expected err to be nil, got %v", err)
 	}
 }
@@ -111,11 +116,11 @@ func TestAdjustWeightsWithInvalidW3(t *testing.T) {
 	if err != nil {
 		t.Errorf("failed to create wrong w3, got %v", err)
 	}
-	nn, err := neuralnet.New(0.001, 0.0001, activation.Sigmoid, activation.SigmoidPrime)
+	nn, err := neuralnet.New(neuralnet.WithLearningRate(0.001), neuralnet.WithRegularizer(0.0001))
 	if err != nil {
 		t.Errorf("failed to create nn, got %v", err)
 	}
-	if err := nn.AdjustWeights(nn.W2(), w3MatrixWithWrongDimensions); err == nil {
+	if err := nn.AdjustWeights(nn.Weights()[0], w3MatrixWithWrongDimensions); err == nil {
 		t.Errorf("expected err to be nil, got %v", err)
 	}
 }
@@ -124,7 +129,14 @@ func TestToJSON(t *testing.T) {
 	expectedLearningRate := 0.001
 	expectedRegularizationFactor := 0.0001
 
-	nn, err := neuralnet.New(expectedLearningRate, expectedRegularizationFactor, activation.Sigmoid, activation.SigmoidPrime)
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:       2,
+		HiddenLayerSizes:     []int{3},
+		OutputLayerSize:      1,
+		LearningRate:         expectedLearningRate,
+		RegularizationFactor: expectedRegularizationFactor,
+		Activation:           activation.NewSigmoid(),
+	})
 	if err != nil {
 		t.Errorf("expected err to be nil, got %v", err)
 	}
@@ -139,12 +151,12 @@ func TestToJSON(t *testing.T) {
 		t.Errorf("failed to create w2, got %v", err)
 	}
 
-	b2, err := matrix.New(3, 3, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9})
+	b2, err := matrix.New(1, 3, []float64{1, 2, 3})
 	if err != nil {
 		t.Errorf("failed to create b2, got %v", err)
 	}
 
-	b3, err := matrix.New(3, 1, []float64{7, 8, 9})
+	b3, err := matrix.New(1, 1, []float64{7})
 	if err != nil {
 		t.Errorf("failed to create b3, got %v", err)
 	}
@@ -161,7 +173,9 @@ func TestToJSON(t *testing.T) {
 	if err != nil {
 		t.Errorf("expected err to be nil, got %v", err)
 	}
-	expectedJson := `{"learningRate":0.001,"regularizationFactor":0.0001,"w2":[1,2,3,4,5,6],"w3":[7,8,9],"b2":[1,2,3,4,5,6,7,8,9],"b3":[7,8,9]}`
+	expectedJson := `{"version":2,"learningRate":0.001,"regularizationFactor":0.0001,"loss":{"name":"mse"},"optimizer":{"name":"sgd"},"layers":[` +
+		`{"type":"dense","activation":{"name":"sigmoid"},"params":[{"name":"weights","rows":2,"columns":3,"values":[1,2,3,4,5,6]},{"name":"biases","rows":1,"columns":3,"values":[1,2,3]}]},` +
+		`{"type":"dense","activation":{"name":"sigmoid"},"params":[{"name":"weights","rows":3,"columns":1,"values":[7,8,9]},{"name":"biases","rows":1,"columns":1,"values":[7]}]}]}`
 	if nnJson != expectedJson {
 		t.Errorf("expected received json to be %s, got %s", expectedJson, nnJson)
 	}
@@ -186,37 +200,37 @@ func TestGradientComponentsCalculationWithKnownInputOutputAndWeights_1(t *testin
 	}
 	X := inputOutput.Input
 	Y := inputOutput.Output
-	nn, err := neuralnet.New(0.001, 0.0001, activation.Sigmoid, activation.SigmoidPrime)
+	nn, err := neuralnet.New(neuralnet.WithLearningRate(0.001), neuralnet.WithRegularizer(0.0001))
 	if err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
 	}
 	expectedDelta3, err := matrix.New(3, 1, []float64{
-		-1.5831834317580953,
-		-1.8776940167768534,
-		-1.2481393907717802,
+		-1.5831834317580955,
+		-1.8318545425422987,
+		-2.0798316550946008,
 	})
 	if err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
 	}
 	expectedDEdW3, err := matrix.New(3, 1, []float64{
-		-0.6620802075143511,
-		-0.9546149779996012,
-		-0.7519211767397317,
+		-1.273997105583833,
+		-1.5286150722004324,
+		-0.500461432587623,
 	})
 	if err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
 	}
 	expectedDelta2, err := matrix.New(3, 3, []float64{
-		-0.14838073302058022, 0.14351516015363522, 0.28797754673577824,
-		-0.16733805844595845, 0.34150686759302057, 0.3140335299621354,
-		-0.11202249593379544, 0.2272101849555621, 0.18488821406208106,
+		-0.1483807330205802, 0.14351516015363522, 0.28797754673577824,
+		-0.16890759751241916, 0.2269881530216686, 0.2475175442712293,
+		-0.19103972702452493, 0.1769048298106681, 0.23392492597435635,
 	})
 	if err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
 	}
 	expectedDEdW2, err := matrix.New(2, 3, []float64{
-		-0.08006490486504064, 0.147088658404093, 0.14266884536413632,
-		-0.0755568160487767, 0.10098182275802815, 0.14166393392825902,
+		-0.10666557173969393, 0.1112337542605697, 0.1479284183864104,
+		-0.08619708279863801, 0.0866398611006188, 0.14376776313716863,
 	})
 	if err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
@@ -233,7 +247,7 @@ func TestGradientComponentsCalculationWithKnownInputOutputAndWeights_1(t *testin
 		t.Errorf("expected error to be nil, got %v", err)
 	}
 
-	evaluationResult, err := nn.Evaluate(Y, forwardResult.Y3)
+	evaluationResult, err := nn.Evaluate(Y, forwardResult.Prediction())
 	if err != nil {
 		t.Errorf("expected error nil, got %v", err)
 	}
@@ -242,13 +256,13 @@ func TestGradientComponentsCalculationWithKnownInputOutputAndWeights_1(t *testin
 		t.Errorf("expected error nil, got %v", err)
 	}
 
-	ensureMatricesAreEqual(t, result.W2, knownW2)
-	ensureMatricesAreEqual(t, result.W3, knownW3)
+	ensureMatricesAreEqual(t, result.Weights[0], knownW2)
+	ensureMatricesAreEqual(t, result.Weights[1], knownW3)
 	ensureMatricesAreEqual(t, result.X, X)
-	ensureMatricesMatch(t, result.Delta3, expectedDelta3, fmt.Sprintf("received Delta3 does not match with expected. Received delta3:\n%s\nExpected delta3:\n%s\n", result.Delta3.ToString(), expectedDelta3.ToString()))
-	ensureMatricesMatch(t, result.DEdW3, expectedDEdW3, fmt.Sprintf("received dEdW3 does not match with expected. Received dEdW3:\n%s\nExpected dEdW3:\n%s\n", result.DEdW3.ToString(), expectedDEdW3.ToString()))
-	ensureMatricesMatch(t, result.Delta2, expectedDelta2, fmt.Sprintf("received Delta2 does not match with expected. Received delta2:\n%s\nExpected delta2:\n%s\n", result.Delta2.ToString(), expectedDelta2.ToString()))
-	ensureMatricesMatch(t, result.DEdW2, expectedDEdW2, fmt.Sprintf("received dEdW2 does not match with expected. Received dEdW2:\n%s\nExpected dEdW2:\n%s\n", result.DEdW2.ToString(), expectedDEdW2.ToString()))
+	ensureMatricesMatch(t, result.Delta[1], expectedDelta3, fmt.Sprintf("received Delta3 does not match with expected. Received delta3:\n%s\nExpected delta3:\n%s\n", result.Delta[1].ToString(), expectedDelta3.ToString()))
+	ensureMatricesMatch(t, result.DEdW[1], expectedDEdW3, fmt.Sprintf("received dEdW3 does not match with expected. Received dEdW3:\n%s\nExpected dEdW3:\n%s\n", result.DEdW[1].ToString(), expectedDEdW3.ToString()))
+	ensureMatricesMatch(t, result.Delta[0], expectedDelta2, fmt.Sprintf("received Delta2 does not match with expected. Received delta2:\n%s\nExpected delta2:\n%s\n", result.Delta[0].ToString(), expectedDelta2.ToString()))
+	ensureMatricesMatch(t, result.DEdW[0], expectedDEdW2, fmt.Sprintf("received dEdW2 does not match with expected. Received dEdW2:\n%s\nExpected dEdW2:\n%s\n", result.DEdW[0].ToString(), expectedDEdW2.ToString()))
 }
 
 func TestGradientDescentAccurace_1(t *testing.T) {
@@ -259,16 +273,164 @@ func TestGradientDescentAccurace_1(t *testing.T) {
 	X := sample.Input
 	Y := sample.Output
 
-	nn, err := neuralnet.New(0.001, 0.0001, activation.Sigmoid, activation.SigmoidPrime)
+	nn, err := neuralnet.New(neuralnet.WithLearningRate(0.001), neuralnet.WithRegularizer(0.0001))
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+
+	forwardResult, err := nn.PredictForAnalysisBasedOn(X)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	evaluation, err := nn.Evaluate(Y, forwardResult.Prediction())
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	gradientComponents, err := nn.ComputeGradients(Y, evaluation.Error, forwardResult)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	defaultGradients := flattenParams(append(gradientComponents.DEdW, gradientComponents.DEdB...))
+
+	numericalGradients, err := getNumericalGradient(nn, X, Y)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+
+	diff := normOfSlice(subtractArrays(defaultGradients, numericalGradients)) / normOfSlice(sumArrays(defaultGradients, numericalGradients))
+	acceptedError := 1e-4
+	if diff >= acceptedError {
+		t.Errorf("expected diff to be < %v, got %v", acceptedError, diff)
+	}
+}
+
+func TestGradientsOfBiasesAreRegularized(t *testing.T) {
+	sample, err := sample.GetAReadyInputAndOutputSample()
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	gradientsOfBiases := func(regularizationFactor float64) []*matrix.Matrix {
+		nn, err := neuralnet.New(
+			neuralnet.WithRegularizer(regularizationFactor),
+			neuralnet.WithBiasInitializer(initializer.NewConstant(0.5)),
+			neuralnet.WithSeed(1),
+		)
+		if err != nil {
+			t.Fatalf("expected error to be nil, got %v", err)
+		}
+		forwardResult, err := nn.PredictForAnalysisBasedOn(sample.Input)
+		if err != nil {
+			t.Fatalf("expected error to be nil, got %v", err)
+		}
+		evaluation, err := nn.Evaluate(sample.Output, forwardResult.Prediction())
+		if err != nil {
+			t.Fatalf("expected error to be nil, got %v", err)
+		}
+		gradients, err := nn.ComputeGradients(sample.Output, evaluation.Error, forwardResult)
+		if err != nil {
+			t.Fatalf("expected error to be nil, got %v", err)
+		}
+		return gradients.DEdB
+	}
+
+	plain, regularized := gradientsOfBiases(0), gradientsOfBiases(0.1)
+
+	for i := range plain {
+		for j, value := range regularized[i].FlattenedElements() {
+			if expected := plain[i].FlattenedElements()[j] + 0.1*0.5; math.Abs(value-expected) > 1e-12 {
+				t.Errorf("expected gradient %d of biases %d to be %v, got %v", j, i, expected, value)
+			}
+		}
+	}
+}
+
+func TestNewWithConfigWithInvalidLayerSize(t *testing.T) {
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:   0,
+		HiddenLayerSizes: []int{3},
+		OutputLayerSize:  1,
+		Activation:       activation.NewSigmoid(),
+	})
+	if err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+	if nn != nil {
+		t.Errorf("expected nn to be nil")
+	}
+}
+
+func TestNewWithConfigBuildsGivenTopology(t *testing.T) {
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:       12,
+		HiddenLayerSizes:     []int{32},
+		OutputLayerSize:      4,
+		LearningRate:         0.001,
+		RegularizationFactor: 0.0001,
+		Activation:           activation.NewSigmoid(),
+	})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	X, err := matrix.New(5, 12, make([]float64, 5*12))
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	Y, err := matrix.New(5, 4, make([]float64, 5*4))
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	forwardResult, err := nn.PredictForAnalysisBasedOn(X)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if forwardResult.Prediction().Rows != 5 || forwardResult.Prediction().Columns != 4 {
+		t.Errorf("expected prediction to be (5x4), got (%dx%d)", forwardResult.Prediction().Rows, forwardResult.Prediction().Columns)
+	}
+	evaluation, err := nn.Evaluate(Y, forwardResult.Prediction())
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	gradients, err := nn.ComputeGradients(Y, evaluation.Error, forwardResult)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if gradients.DEdW[0].Rows != 12 || gradients.DEdW[0].Columns != 32 {
+		t.Errorf("expected dEdW2 to be (12x32), got (%dx%d)", gradients.DEdW[0].Rows, gradients.DEdW[0].Columns)
+	}
+	if gradients.DEdW[1].Rows != 32 || gradients.DEdW[1].Columns != 4 {
+		t.Errorf("expected dEdW3 to be (32x4), got (%dx%d)", gradients.DEdW[1].Rows, gradients.DEdW[1].Columns)
+	}
+}
+
+func TestGradientDescentAccuraceWithDeepNetwork(t *testing.T) {
+	sample, err := sample.GetAReadyInputAndOutputSample()
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	X := sample.Input
+	Y := sample.Output
+
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:       2,
+		HiddenLayerSizes:     []int{4, 3, 5},
+		OutputLayerSize:      1,
+		LearningRate:         0.001,
+		RegularizationFactor: 0.0001,
+		Activation:           activation.NewSigmoid(),
+	})
 	if err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
 	}
+	if len(nn.Layers()) != 4 {
+		t.Errorf("expected nn to have 4 layers, got %d", len(nn.Layers()))
+	}
 
 	forwardResult, err := nn.PredictForAnalysisBasedOn(X)
 	if err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
 	}
-	evaluation, err := nn.Evaluate(Y, forwardResult.Y3)
+	evaluation, err := nn.Evaluate(Y, forwardResult.Prediction())
 	if err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
 	}
@@ -276,7 +438,7 @@ func TestGradientDescentAccurace_1(t *testing.T) {
 	if err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
 	}
-	defaultGradients := flattenParams(gradientComponents.DEdW2, gradientComponents.DEdW3, gradientComponents.DEdB2, gradientComponents.DEdB3)
+	defaultGradients := flattenParams(append(gradientComponents.DEdW, gradientComponents.DEdB...))
 
 	numericalGradients, err := getNumericalGradient(nn, X, Y)
 	if err != nil {
@@ -289,3 +451,404 @@ func TestGradientDescentAccurace_1(t *testing.T) {
 		t.Errorf("expected diff to be < %v, got %v", acceptedError, diff)
 	}
 }
+
+func TestPredictWithBatchesOfAnySize(t *testing.T) {
+	nn, err := neuralnet.New(neuralnet.WithLearningRate(0.001), neuralnet.WithRegularizer(0.0001))
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	for _, rows := range []int{1, 3, 7} {
+		X, err := matrix.New(rows, 2, make([]float64, rows*2))
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		prediction, err := nn.PredictBasedOn(X)
+		if err != nil {
+			t.Errorf("expected error to be nil for %d rows, got %v", rows, err)
+			continue
+		}
+		if prediction.Rows != rows || prediction.Columns != 1 {
+			t.Errorf("expected prediction to be (%dx1), got (%dx%d)", rows, prediction.Rows, prediction.Columns)
+		}
+		// all rows are equal, so the same bias must have been summed to all of them
+		first, _ := prediction.GetAt(0, 0)
+		last, _ := prediction.GetAt(rows-1, 0)
+		if first != last {
+			t.Errorf("expected every row to get the same bias, got %v and %v", first, last)
+		}
+	}
+}
+
+// newClassifier builds a network with every kind of layer.
+func newClassifier(t *testing.T) *neuralnet.NeuralNet {
+	random := rand.New(neuralnet.NewSource(1))
+	hidden, err := neuralnet.NewDense(2, 4, activation.NewIdentity(), random)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	prelu, err := neuralnet.NewPReLU(4, 0.25)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	output, err := neuralnet.NewDense(4, 3, activation.NewIdentity(), random)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		LearningRate: 0.1,
+		Layers:       []neuralnet.Layer{hidden, prelu, output, neuralnet.NewSoftmax()},
+		Loss:         loss.NewCategoricalCrossEntropy(),
+	})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	return nn
+}
+
+func TestPredictDoesNotReuseOutputs(t *testing.T) {
+	nn := newClassifier(t)
+	first, second := newSamples(t, 3).X, newSamples(t, 5).X
+
+	analysis, err := nn.PredictForAnalysisBasedOn(first)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	prediction, err := nn.PredictBasedOn(first)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	expectedV, expectedPrediction := analysis.V[0].Copy(), prediction.Copy()
+	if _, err := nn.PredictForAnalysisBasedOn(second); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if _, err := nn.PredictBasedOn(second); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	ensureMatricesAreEqual(t, analysis.V[0], expectedV)
+	ensureMatricesAreEqual(t, analysis.Prediction(), expectedPrediction)
+	ensureMatricesAreEqual(t, prediction, expectedPrediction)
+}
+
+func TestPredictForAnalysisCopiesEachOutputOnce(t *testing.T) {
+	nn := newClassifier(t)
+	X, err := matrix.New(2, 2, []float64{0.3, -1, 0.5, 0.2})
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	result, err := nn.PredictForAnalysisBasedOn(X)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	// dense layers keep X*W + B, while the others have no value before their output
+	for i, layer := range nn.Layers() {
+		dense, isDense := layer.(*neuralnet.Dense)
+		if (result.V[i] == result.Y[i]) == isDense {
+			t.Errorf("expected V and Y of layer %d to be the same matrix: %v", i, !isDense)
+		}
+		if isDense {
+			ensureMatricesAreEqual(t, result.V[i], dense.PreActivation())
+		}
+	}
+}
+
+func TestPredictConcurrently(t *testing.T) {
+	nn := newClassifier(t)
+	inputs := make([]*matrix.Matrix, 8)
+	expected := make([]*matrix.Matrix, len(inputs))
+	for i := range inputs {
+		inputs[i] = newSamples(t, i+1).X
+		prediction, err := nn.PredictBasedOn(inputs[i])
+		if err != nil {
+			t.Fatalf("expected error to be nil, got %v", err)
+		}
+		expected[i] = prediction
+	}
+
+	var wg sync.WaitGroup
+	predictions := make([]*matrix.Matrix, len(inputs))
+	for i := range inputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				prediction, err := nn.PredictBasedOn(inputs[i])
+				if err != nil {
+					t.Errorf("expected error to be nil, got %v", err)
+					return
+				}
+				predictions[i] = prediction
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, prediction := range predictions {
+		ensureMatricesAreEqual(t, prediction, expected[i])
+	}
+}
+
+func TestGradientDescentAccuraceWithDifferentBatchSizes(t *testing.T) {
+	nn, err := neuralnet.New(neuralnet.WithLearningRate(0.001), neuralnet.WithRegularizer(0.0001))
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	xData := []float64{0.3, 1, 0.5, 0.2, 1, 0.4, 0.7, 0.6, 0.1, 0.9}
+	yData := []float64{0.75, 0.82, 0.93, 0.61, 0.88}
+	for _, rows := range []int{1, 5} {
+		X, err := matrix.New(rows, 2, xData[:rows*2])
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		Y, err := matrix.New(rows, 1, yData[:rows])
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+
+		forwardResult, err := nn.PredictForAnalysisBasedOn(X)
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		evaluation, err := nn.Evaluate(Y, forwardResult.Prediction())
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		gradientComponents, err := nn.ComputeGradients(Y, evaluation.Error, forwardResult)
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		defaultGradients := flattenParams(append(gradientComponents.DEdW, gradientComponents.DEdB...))
+
+		numericalGradients, err := getNumericalGradient(nn, X, Y)
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+
+		diff := normOfSlice(subtractArrays(defaultGradients, numericalGradients)) / normOfSlice(sumArrays(defaultGradients, numericalGradients))
+		acceptedError := 1e-4
+		if diff >= acceptedError {
+			t.Errorf("expected diff to be < %v for batch of %d rows, got %v", acceptedError, rows, diff)
+		}
+	}
+}
+
+func TestGradientDescentAccuraceWithConfiguredLoss(t *testing.T) {
+	huber, err := loss.NewHuber(0.1)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	X, err := matrix.New(3, 2, []float64{0.3, 1, 0.5, 0.2, 1, 0.4})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	Y, err := matrix.New(3, 1, []float64{1, 0, 1})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	for _, l := range []loss.Loss{huber, loss.NewLogCosh(), loss.NewBinaryCrossEntropy()} {
+		nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+			InputLayerSize:       2,
+			HiddenLayerSizes:     []int{3},
+			OutputLayerSize:      1,
+			RegularizationFactor: 0.0001,
+			Activation:           activation.NewSigmoid(),
+			Loss:                 l,
+		})
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+
+		forwardResult, err := nn.PredictForAnalysisBasedOn(X)
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		evaluation, err := nn.Evaluate(Y, forwardResult.Prediction())
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		gradientComponents, err := nn.ComputeGradients(Y, evaluation.Error, forwardResult)
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		defaultGradients := flattenParams(append(gradientComponents.DEdW, gradientComponents.DEdB...))
+
+		numericalGradients, err := getNumericalGradient(nn, X, Y)
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+
+		diff := normOfSlice(subtractArrays(defaultGradients, numericalGradients)) / normOfSlice(sumArrays(defaultGradients, numericalGradients))
+		acceptedError := 1e-4
+		if diff >= acceptedError {
+			t.Errorf("expected diff to be < %v with %s loss, got %v", acceptedError, l.Name(), diff)
+		}
+	}
+}
+
+func TestNewWithConfigUsesOutputActivationOnLastLayer(t *testing.T) {
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:   2,
+		HiddenLayerSizes: []int{4, 3},
+		OutputLayerSize:  1,
+		Activation:       activation.NewReLU(),
+		OutputActivation: activation.NewIdentity(),
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	expectedNames := []string{"relu", "relu", "identity"}
+	for i, layer := range nn.Layers() {
+		dense, ok := layer.(*neuralnet.Dense)
+		if !ok {
+			t.Errorf("expected layer %d to be dense", i)
+			continue
+		}
+		if name := dense.Activation().Name; name != expectedNames[i] {
+			t.Errorf("expected layer %d to use %s, got %s", i, expectedNames[i], name)
+		}
+	}
+}
+
+func TestNewWithConfigWithoutActivation(t *testing.T) {
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:   2,
+		HiddenLayerSizes: []int{3},
+		OutputLayerSize:  1,
+		OutputActivation: activation.NewIdentity(),
+	})
+	if err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+	if nn != nil {
+		t.Errorf("expected nn to be nil")
+	}
+}
+
+func TestGradientDescentAccuraceWithUnboundedOutput(t *testing.T) {
+	X, err := matrix.New(3, 2, []float64{0.3, 1, 0.5, 0.2, 1, 0.4})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	Y, err := matrix.New(3, 1, []float64{7.5, -8.2, 9.3})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	for _, hidden := range []activation.Activation{activation.NewTanh(), activation.NewELU(1), activation.NewGELU(), activation.NewSiLU()} {
+		nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+			InputLayerSize:       2,
+			HiddenLayerSizes:     []int{3},
+			OutputLayerSize:      1,
+			RegularizationFactor: 0.0001,
+			Activation:           hidden,
+			OutputActivation:     activation.NewIdentity(),
+		})
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+
+		forwardResult, err := nn.PredictForAnalysisBasedOn(X)
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		evaluation, err := nn.Evaluate(Y, forwardResult.Prediction())
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		gradientComponents, err := nn.ComputeGradients(Y, evaluation.Error, forwardResult)
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		defaultGradients := flattenParams(append(gradientComponents.DEdW, gradientComponents.DEdB...))
+
+		numericalGradients, err := getNumericalGradient(nn, X, Y)
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+
+		diff := normOfSlice(subtractArrays(defaultGradients, numericalGradients)) / normOfSlice(sumArrays(defaultGradients, numericalGradients))
+		acceptedError := 1e-4
+		if diff >= acceptedError {
+			t.Errorf("expected diff to be < %v with %s hidden layer, got %v", acceptedError, hidden.Name, diff)
+		}
+	}
+}
+
+func TestNewWithConfigUsesInitializerOfEachLayer(t *testing.T) {
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:     2,
+		HiddenLayerSizes:   []int{3},
+		OutputLayerSize:    1,
+		Activation:         activation.NewSigmoid(),
+		WeightInitializers: []initializer.Initializer{initializer.NewConstant(0.5)},
+		BiasInitializer:    initializer.NewConstant(0.1),
+		Source:             neuralnet.NewSource(1),
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	for _, value := range nn.Weights()[0].FlattenedElements() {
+		if value != 0.5 {
+			t.Errorf("expected weights of the hidden layer to be 0.5, got %v", value)
+		}
+	}
+	// the output layer has no initializer, so it uses the default one
+	if w3 := nn.Weights()[1].FlattenedElements(); w3[0] == 0.5 && w3[1] == 0.5 && w3[2] == 0.5 {
+		t.Errorf("expected weights of the output layer to be random, got %v", w3)
+	}
+	for _, b := range nn.Biases() {
+		for _, value := range b.FlattenedElements() {
+			if value != 0.1 {
+				t.Errorf("expected biases to be 0.1, got %v", value)
+			}
+		}
+	}
+}
+
+func TestNewWithConfigWithTooManyInitializers(t *testing.T) {
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:     2,
+		HiddenLayerSizes:   []int{3},
+		OutputLayerSize:    1,
+		Activation:         activation.NewSigmoid(),
+		WeightInitializers: []initializer.Initializer{initializer.NewZeros(), initializer.NewZeros(), initializer.NewZeros()},
+	})
+	if err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+	if nn != nil {
+		t.Errorf("expected nn to be nil")
+	}
+}
+
+func TestNewDenseUsesInitializerSuitingTheActivation(t *testing.T) {
+	random := rand.New(neuralnet.NewSource(1))
+	fanIn, fanOut := 400, 300
+	testCases := []struct {
+		activation                activation.Activation
+		expectedStandardDeviation float64
+	}{
+		{activation: activation.NewReLU(), expectedStandardDeviation: math.Sqrt(2.0 / float64(fanIn))},
+		{activation: activation.NewSELU(), expectedStandardDeviation: math.Sqrt(1.0 / float64(fanIn))},
+		{activation: activation.NewTanh(), expectedStandardDeviation: math.Sqrt(2.0 / float64(fanIn+fanOut))},
+	}
+	for _, testCase := range testCases {
+		dense, err := neuralnet.NewDense(fanIn, fanOut, testCase.activation, random)
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+			continue
+		}
+		sumOfSquares, err := dense.Weights().Norm(2)
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		standardDeviation := sumOfSquares / math.Sqrt(float64(fanIn*fanOut))
+		if math.Abs(standardDeviation-testCase.expectedStandardDeviation) > 0.02*testCase.expectedStandardDeviation {
+			t.Errorf("expected weights of %s layer to have standard deviation %v, got %v", testCase.activation.Name, testCase.expectedStandardDeviation, standardDeviation)
+		}
+		if norm, _ := dense.Biases().Norm(1); norm != 0 {
+			t.Errorf("expected biases to be zero, got norm %v", norm)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package neuralnet
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/buarki/supervised-machine-learning/activation"
+	"github.com/buarki/supervised-machine-learning/initializer"
+	"github.com/buarki/supervised-machine-learning/loss"
+	"github.com/buarki/supervised-machine-learning/optimizer"
+)
+
+// Option changes how New builds the network.
+type Option func(o *options)
+
+// options holds the config being built by New along with the
+// problems found while applying each option.
+type options struct {
+	config   Config
+	problems []error
+	seeded   bool
+	sourced  bool
+}
+
+// ConfigError reports every problem found on the options or
+// config given to build a neural network.
+type ConfigError struct {
+	Problems []error
+}
+
+func (e *ConfigError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Error()
+	}
+	return fmt.Sprintf("invalid neural net config: %s", strings.Join(messages, "; "))
+}
+
+// New creates and returns a neural network built from the given options.
+// Without options it has two inputs, three sigmoid hidden neurons and one
+// output, learns at a rate of 0.01 using plain gradient descent and half
+// the squared error. All invalid options are reported together as a
+// *ConfigError.
+func New(opts ...Option) (*NeuralNet, error) {
+	o := &options{
+		config: Config{
+			InputLayerSize:   inputLayerSize,
+			HiddenLayerSizes: []int{hiddenLayerSize},
+			OutputLayerSize:  outputLayerSize,
+			LearningRate:     defaultLearningRate,
+			Activation:       activation.NewSigmoid(),
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.seeded && o.sourced {
+		o.problems = append(o.problems, errors.New("seed and source cannot be both given"))
+	}
+	problems := append(o.problems, o.config.problems()...)
+	if len(problems) > 0 {
+		return nil, &ConfigError{Problems: problems}
+	}
+	return NewWithConfig(o.config)
+}
+
+// WithLayers sets the size of each layer, from the input one to the
+// output one, so WithLayers(2, 3, 1) gives the default topology.
+func WithLayers(sizes ...int) Option {
+	return func(o *options) {
+		if len(sizes) < 2 {
+			o.problems = append(o.problems, fmt.Errorf("at least the input and output layer sizes must be given, received %d sizes", len(sizes)))
+			return
+		}
+		o.config.InputLayerSize = sizes[0]
+		o.config.HiddenLayerSizes = append([]int{}, sizes[1:len(sizes)-1]...)
+		o.config.OutputLayerSize = sizes[len(sizes)-1]
+	}
+}
+
+// WithActivation sets the activation of the hidden layers, which is
+// also used by the output one unless WithOutputActivation is given.
+func WithActivation(a activation.Activation) Option {
+	return func(o *options) {
+		o.config.Activation = a
+	}
+}
+
+// WithOutputActivation sets the activation of the output layer.
+func WithOutputActivation(a activation.Activation) Option {
+	return func(o *options) {
+		o.config.OutputActivation = a
+	}
+}
+
+// WithSoftmaxOutput makes the network predict the probability of
+// each output class, see Config.SoftmaxOutput.
+func WithSoftmaxOutput() Option {
+	return func(o *options) {
+		o.config.SoftmaxOutput = true
+	}
+}
+
+// WithLoss sets the loss used to evaluate predictions.
+func WithLoss(l loss.Loss) Option {
+	return func(o *options) {
+		if l == nil {
+			o.problems = append(o.problems, errors.New("loss cannot be nil"))
+			return
+		}
+		o.config.Loss = l
+	}
+}
+
+// WithOptimizer sets the optimizer used to update params during train.
+func WithOptimizer(opt optimizer.Optimizer) Option {
+	return func(o *options) {
+		if opt == nil {
+			o.problems = append(o.problems, errors.New("optimizer cannot be nil"))
+			return
+		}
+		o.config.Optimizer = opt
+	}
+}
+
+// WithLearningRate sets the learning rate.
+func WithLearningRate(learningRate float64) Option {
+	return func(o *options) {
+		o.config.LearningRate = learningRate
+	}
+}
+
+// WithRegularizer sets the factor of the L2 regularization
+// applied to the weights. No regularization is used by default.
+func WithRegularizer(regularizationFactor float64) Option {
+	return func(o *options) {
+		o.config.RegularizationFactor = regularizationFactor
+	}
+}
+
+// WithSeed makes the initial params and the train be the same on
+// every run with the same seed.
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.seeded = true
+		o.config.Source = NewSource(seed)
+	}
+}
+
+// WithSource sets the source of randomness of the network.
+func WithSource(source rand.Source) Option {
+	return func(o *options) {
+		if source == nil {
+			o.problems = append(o.problems, errors.New("source cannot be nil"))
+			return
+		}
+		o.sourced = true
+		o.config.Source = source
+	}
+}
+
+// WithInitializer sets the initializer of the weights of every layer.
+func WithInitializer(weights initializer.Initializer) Option {
+	return func(o *options) {
+		if weights == nil {
+			o.problems = append(o.problems, errors.New("initializer cannot be nil"))
+			return
+		}
+		o.config.WeightInitializer = weights
+	}
+}
+
+// WithLayerInitializers sets the initializer of the weights of each
+// layer, from the first hidden one to the output one.
+func WithLayerInitializers(weights ...initializer.Initializer) Option {
+	return func(o *options) {
+		o.config.WeightInitializers = weights
+	}
+}
+
+// WithBiasInitializer sets the initializer of the biases of every layer.
+func WithBiasInitializer(biases initializer.Initializer) Option {
+	return func(o *options) {
+		if biases == nil {
+			o.problems = append(o.problems, errors.New("bias initializer cannot be nil"))
+			return
+		}
+		o.config.BiasInitializer = biases
+	}
+}
+
+// validate returns a *ConfigError listing the problems of the
+// config, or nil when it can be used to build a network.
+func (config Config) validate() error {
+	if problems := config.problems(); len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+	return nil
+}
+
+func (config Config) problems() []error {
+	var problems []error
+	if config.LearningRate < 0 {
+		problems = append(problems, fmt.Errorf("learning rate must be >= 0, received %v", config.LearningRate))
+	}
+	if config.RegularizationFactor < 0 {
+		problems = append(problems, fmt.Errorf("regularization factor must be >= 0, received %v", config.RegularizationFactor))
+	}
+	softmaxOutput := config.SoftmaxOutput
+	if len(config.Layers) > 0 {
+		_, softmaxOutput = config.Layers[len(config.Layers)-1].(*Softmax)
+	} else {
+		problems = append(problems, config.layerProblems()...)
+	}
+	if _, ok := config.Loss.(*loss.SoftmaxCrossEntropy); ok && softmaxOutput {
+		problems = append(problems, errors.New("softmax cross entropy cannot be used with a softmax output, use categorical cross entropy instead"))
+	}
+	return problems
+}
+
+func (config Config) layerProblems() []error {
+	var problems []error
+	if config.InputLayerSize <= 0 {
+		problems = append(problems, fmt.Errorf("input layer size must be > 0, received %d", config.InputLayerSize))
+	}
+	for i, size := range config.HiddenLayerSizes {
+		if size <= 0 {
+			problems = append(problems, fmt.Errorf("hidden layer %d size must be > 0, received %d", i, size))
+		}
+	}
+	if config.OutputLayerSize <= 0 {
+		problems = append(problems, fmt.Errorf("output layer size must be > 0, received %d", config.OutputLayerSize))
+	}
+	if config.SoftmaxOutput && config.OutputLayerSize == 1 {
+		problems = append(problems, errors.New("softmax output needs at least 2 classes, received 1"))
+	}
+	layers := len(config.HiddenLayerSizes) + 1
+	if len(config.WeightInitializers) > layers {
+		problems = append(problems, fmt.Errorf("expected at most %d weight initializers, received %d", layers, len(config.WeightInitializers)))
+	}
+	problems = append(problems, config.initializerProblems()...)
+	// the output activation only matters when it replaces the hidden one
+	hidden := len(config.HiddenLayerSizes) > 0
+	outputActivationSet := config.OutputActivation.Function != nil || config.OutputActivation.Derivative != nil
+	if err := config.Activation.Validate(); err != nil && (hidden || (!outputActivationSet && !config.SoftmaxOutput)) {
+		problems = append(problems, fmt.Errorf("invalid activation, got %v", err))
+	}
+	if err := config.OutputActivation.Validate(); err != nil && outputActivationSet {
+		problems = append(problems, fmt.Errorf("invalid output activation, got %v", err))
+	}
+	return problems
+}
+
+// initializerProblems reports the initializers only giving params
+// of a shape other than the one of the layer they initialize.
+func (config Config) initializerProblems() []error {
+	var problems []error
+	sizes := config.sizes()
+	for i := 1; i < len(sizes); i++ {
+		if sizes[i-1] <= 0 || sizes[i] <= 0 {
+			continue
+		}
+		if rows, columns, ok := shapeOf(config.weightsInitializer(i - 1)); ok && (rows != sizes[i-1] || columns != sizes[i]) {
+			problems = append(problems, fmt.Errorf("weights initializer of layer %d gives (%dx%d) params, expected (%dx%d)", i+1, rows, columns, sizes[i-1], sizes[i]))
+		}
+		if rows, columns, ok := shapeOf(config.BiasInitializer); ok && (rows != 1 || columns != sizes[i]) {
+			problems = append(problems, fmt.Errorf("biases initializer of layer %d gives (%dx%d) params, expected (1x%d)", i+1, rows, columns, sizes[i]))
+		}
+	}
+	return problems
+}
+
+// shapeOf returns the only shape the initializer gives params of, if any.
+func shapeOf(i initializer.Initializer) (int, int, bool) {
+	shaped, ok := i.(initializer.Shaped)
+	if !ok {
+		return 0, 0, false
+	}
+	rows, columns := shaped.Shape()
+	return rows, columns, true
+}
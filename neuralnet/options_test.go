@@ -0,0 +1,161 @@
+package neuralnet_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/activation"
+	"github.com/buarki/supervised-machine-learning/initializer"
+	"github.com/buarki/supervised-machine-learning/loss"
+	"github.com/buarki/supervised-machine-learning/matrix"
+	"github.com/buarki/supervised-machine-learning/neuralnet"
+)
+
+func TestNewWithoutOptionsUsesDefaultTopology(t *testing.T) {
+	nn, err := neuralnet.New()
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	weights := nn.Weights()
+	if len(weights) != 2 {
+		t.Fatalf("expected 2 weights, got %d", len(weights))
+	}
+	if weights[0].Rows != 2 || weights[0].Columns != 3 {
+		t.Errorf("expected first weights to be 2x3, got %dx%d", weights[0].Rows, weights[0].Columns)
+	}
+	if weights[1].Rows != 3 || weights[1].Columns != 1 {
+		t.Errorf("expected second weights to be 3x1, got %dx%d", weights[1].Rows, weights[1].Columns)
+	}
+}
+
+func TestNewWithOptions(t *testing.T) {
+	nn, err := neuralnet.New(
+		neuralnet.WithLayers(4, 5, 3),
+		neuralnet.WithActivation(activation.NewTanh()),
+		neuralnet.WithSoftmaxOutput(),
+		neuralnet.WithInitializer(initializer.NewConstant(0.5)),
+		neuralnet.WithBiasInitializer(initializer.NewConstant(0.1)),
+		neuralnet.WithSeed(1),
+	)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	layers := nn.Layers()
+	if len(layers) != 3 {
+		t.Fatalf("expected 3 layers, got %d", len(layers))
+	}
+	if _, ok := layers[2].(*neuralnet.Softmax); !ok {
+		t.Errorf("expected last layer to be a softmax")
+	}
+	if name := layers[0].(*neuralnet.Dense).Activation().Name; name != "tanh" {
+		t.Errorf("expected hidden layer to use tanh, got %s", name)
+	}
+	for i, weights := range nn.Weights() {
+		for _, value := range weights.FlattenedElements() {
+			if value != 0.5 {
+				t.Errorf("expected weights %d to be 0.5, got %v", i, value)
+			}
+		}
+	}
+	for i, biases := range nn.Biases() {
+		for _, value := range biases.FlattenedElements() {
+			if value != 0.1 {
+				t.Errorf("expected biases %d to be 0.1, got %v", i, value)
+			}
+		}
+	}
+}
+
+func TestNewWithSameSeedGivesSameWeights(t *testing.T) {
+	first, err := neuralnet.New(neuralnet.WithSeed(42))
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	second, err := neuralnet.New(neuralnet.WithSeed(42))
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	for i := range first.Weights() {
+		ensureMatricesAreEqual(t, first.Weights()[i], second.Weights()[i])
+	}
+}
+
+func TestNewReportsEveryInvalidOption(t *testing.T) {
+	nn, err := neuralnet.New(
+		neuralnet.WithLayers(0, -1, 1),
+		neuralnet.WithLearningRate(-1),
+		neuralnet.WithRegularizer(-1),
+		neuralnet.WithActivation(activation.Activation{}),
+		neuralnet.WithLoss(nil),
+		neuralnet.WithOptimizer(nil),
+		neuralnet.WithSeed(1),
+		neuralnet.WithSource(neuralnet.NewSource(1)),
+	)
+	if err == nil {
+		t.Fatalf("expected err to be not nil")
+	}
+	if nn != nil {
+		t.Errorf("expected nn to be nil")
+	}
+	var configErr *neuralnet.ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("expected err to be a config error, got %v", err)
+	}
+	if len(configErr.Problems) != 8 {
+		t.Errorf("expected 8 problems, got %d: %v", len(configErr.Problems), err)
+	}
+}
+
+func fromMatrix(t *testing.T, rows, columns int, values []float64) initializer.Initializer {
+	m, err := matrix.New(rows, columns, values)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	i, err := initializer.NewFromMatrix(m)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	return i
+}
+
+func TestNewReportsInitializersOfWrongShape(t *testing.T) {
+	first := fromMatrix(t, 3, 2, []float64{1, 2, 3, 4, 5, 6})
+	output := fromMatrix(t, 2, 1, []float64{1, 2})
+	biases := fromMatrix(t, 1, 3, []float64{1, 2, 3})
+
+	// the first layer is (2x3) and the output one (3x1), while biases only fit the first
+	_, err := neuralnet.New(
+		neuralnet.WithLayers(2, 3, 1),
+		neuralnet.WithLearningRate(-1),
+		neuralnet.WithLayerInitializers(first, output),
+		neuralnet.WithBiasInitializer(biases),
+	)
+	var configErr *neuralnet.ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("expected err to be a config error, got %v", err)
+	}
+	if len(configErr.Problems) != 4 {
+		t.Errorf("expected 4 problems, got %d: %v", len(configErr.Problems), err)
+	}
+}
+
+func TestNewWithSoftmaxOutputAndInvalidLoss(t *testing.T) {
+	_, err := neuralnet.New(
+		neuralnet.WithLayers(2, 1),
+		neuralnet.WithSoftmaxOutput(),
+		neuralnet.WithLoss(loss.NewSoftmaxCrossEntropy()),
+	)
+	var configErr *neuralnet.ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("expected err to be a config error, got %v", err)
+	}
+	if len(configErr.Problems) != 2 {
+		t.Errorf("expected 2 problems, got %d: %v", len(configErr.Problems), err)
+	}
+}
+
+func TestNewWithTooFewLayers(t *testing.T) {
+	if _, err := neuralnet.New(neuralnet.WithLayers(2)); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+}
@@ -0,0 +1,126 @@
+package neuralnet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+// PReLU is a parametric ReLU layer. It computes Y = X for positive
+// values and Y = alpha*X otherwise, where each neuron has its own
+// alpha that is learned during train. It is meant to follow a Dense
+// layer using activation.NewIdentity().
+type PReLU struct {
+	alphas *Parameter
+
+	input *matrix.Matrix // X received on last forward
+	y     buffer         // Y computed on last forward
+
+	// matrices computed by backward, kept to be reused
+	alphasGradient, inputGradient buffer
+}
+
+// NewPReLU creates a PReLU layer for size neurons with
+// every alpha starting at initialAlpha.
+func NewPReLU(size int, initialAlpha float64) (*PReLU, error) {
+	initialAlphas := make([]float64, size)
+	for i := range initialAlphas {
+		initialAlphas[i] = initialAlpha
+	}
+	alphas, err := matrix.New(1, size, initialAlphas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create alphas, got %v", err)
+	}
+	return &PReLU{
+		alphas: &Parameter{Name: ParameterAlphas, Value: alphas},
+	}, nil
+}
+
+func (p *PReLU) Alphas() *matrix.Matrix {
+	return p.alphas.Value
+}
+
+func (p *PReLU) Params() []*Parameter {
+	return []*Parameter{p.alphas}
+}
+
+func (p *PReLU) Forward(X *matrix.Matrix) (*matrix.Matrix, error) {
+	y, err := p.forwardInto(&p.y, X)
+	if err != nil {
+		return nil, err
+	}
+	p.input = X
+	return y, nil
+}
+
+// predict runs forward on a new matrix without caching anything.
+func (p *PReLU) predict(X *matrix.Matrix) (*matrix.Matrix, error) {
+	var y buffer
+	return p.forwardInto(&y, X)
+}
+
+func (p *PReLU) forwardInto(yBuffer *buffer, X *matrix.Matrix) (*matrix.Matrix, error) {
+	if X == nil {
+		return nil, errors.New("param x cannot be nil")
+	}
+	if X.Columns != p.alphas.Value.Columns {
+		return nil, fmt.Errorf("expected x to have %d columns, received %d", p.alphas.Value.Columns, X.Columns)
+	}
+	y, err := yBuffer.shaped(X.Rows, X.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create y, got %v", err)
+	}
+	if err := matrix.CopyInto(y, X); err != nil {
+		return nil, fmt.Errorf("failed to copy x to y, got %v", err)
+	}
+	alphas := p.alphas.Value.FlattenedElements()
+	for i := 0; i < X.Rows; i++ {
+		for j := 0; j < X.Columns; j++ {
+			value, _ := X.GetAt(i, j)
+			if value <= 0 {
+				yBuffer.data[i*X.Columns+j] = alphas[j] * value
+			}
+		}
+	}
+	return y, nil
+}
+
+// Backward stores the sum over the batch of dE/dY * min(X, 0) as the
+// gradient of alphas and returns dE/dY scaled by 1 or alpha.
+func (p *PReLU) Backward(outputGradient *matrix.Matrix) (*matrix.Matrix, error) {
+	if p.input == nil {
+		return nil, errors.New("backward called before forward")
+	}
+	if outputGradient.Rows != p.input.Rows || outputGradient.Columns != p.input.Columns {
+		return nil, fmt.Errorf("expected output gradient to be (%dx%d), received (%dx%d)", p.input.Rows, p.input.Columns, outputGradient.Rows, outputGradient.Columns)
+	}
+	alphas := p.alphas.Value.FlattenedElements()
+	alphasGradient, err := p.alphasGradient.shaped(1, len(alphas))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gradient of alphas, got %v", err)
+	}
+	for j := range p.alphasGradient.data {
+		p.alphasGradient.data[j] = 0
+	}
+	inputGradient, err := p.inputGradient.shaped(outputGradient.Rows, outputGradient.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create input gradient, got %v", err)
+	}
+	if err := matrix.CopyInto(inputGradient, outputGradient); err != nil {
+		return nil, fmt.Errorf("failed to copy output gradient to input gradient, got %v", err)
+	}
+	for i := 0; i < p.input.Rows; i++ {
+		for j := 0; j < p.input.Columns; j++ {
+			value, _ := p.input.GetAt(i, j)
+			if value > 0 {
+				continue
+			}
+			gradient, _ := outputGradient.GetAt(i, j)
+			p.alphasGradient.data[j] += gradient * value
+			p.inputGradient.data[i*p.input.Columns+j] = gradient * alphas[j]
+		}
+	}
+	p.alphas.Gradient = alphasGradient
+	return inputGradient, nil
+}
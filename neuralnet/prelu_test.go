@@ -0,0 +1,125 @@
+package neuralnet_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/activation"
+	"github.com/buarki/supervised-machine-learning/matrix"
+	"github.com/buarki/supervised-machine-learning/neuralnet"
+)
+
+func TestPReLUForward(t *testing.T) {
+	layer, err := neuralnet.NewPReLU(2, 0.25)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	X, err := matrix.New(2, 2, []float64{-2, 3, 4, -8})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	expected, err := matrix.New(2, 2, []float64{-0.5, 3, 4, -2})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	y, err := layer.Forward(X)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	ensureMatricesAreEqual(t, y, expected)
+}
+
+func TestPReLUBackwardMatchesNumericalGradient(t *testing.T) {
+	layer, err := neuralnet.NewPReLU(3, 0.1)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	X, err := matrix.New(2, 3, []float64{-1.5, 0.7, -0.2, 2, -3, -0.9})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	// with E = sum of Y, dE/dY is all ones
+	outputGradient, err := matrix.New(2, 3, []float64{1, 1, 1, 1, 1, 1})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	errorOf := func() float64 {
+		y, err := layer.Forward(X)
+		if err != nil {
+			t.Errorf("expected err to be nil, got %v", err)
+		}
+		return y.SumOfAllElements()
+	}
+
+	errorOf()
+	if _, err := layer.Backward(outputGradient); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	gradient := layer.Params()[0].Gradient.FlattenedElements()
+	alphas := layer.Alphas().FlattenedElements()
+	smallDifference := 1e-4
+	for i := range alphas {
+		original, _ := layer.Alphas().GetAt(0, i)
+		_ = layer.Alphas().SetAt(0, i, original+smallDifference)
+		errorAtRight := errorOf()
+		_ = layer.Alphas().SetAt(0, i, original-smallDifference)
+		errorAtLeft := errorOf()
+		_ = layer.Alphas().SetAt(0, i, original)
+
+		numerical := (errorAtRight - errorAtLeft) / (2 * smallDifference)
+		if math.Abs(gradient[i]-numerical) > 1e-6 {
+			t.Errorf("expected gradient of alpha %d to be %v, got %v", i, numerical, gradient[i])
+		}
+	}
+}
+
+func TestTrainUpdatesPReLUAlphas(t *testing.T) {
+	random := rand.New(neuralnet.NewSource(1))
+	dense, err := neuralnet.NewDense(2, 3, activation.NewIdentity(), random)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	prelu, err := neuralnet.NewPReLU(3, 0.25)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	output, err := neuralnet.NewDense(3, 1, activation.NewIdentity(), random)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		LearningRate: 0.01,
+		Layers:       []neuralnet.Layer{dense, prelu, output},
+	})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if len(nn.Params()) != 5 {
+		t.Errorf("expected 5 params, got %d", len(nn.Params()))
+	}
+	X, err := matrix.New(3, 2, []float64{-3, 1, 0.5, -2, -1, -0.4})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	Y, err := matrix.New(3, 1, []float64{-5, 2, 8})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	// params are trained in place, so the initial ones are copied
+	initialAlphas := prelu.Alphas().Copy().FlattenedElements()
+
+	if _, err := neuralnet.Train(nn, 5, []neuralnet.TrainingData{{X: X, Y: Y}}); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	trainedAlphas := prelu.Alphas().FlattenedElements()
+	changed := false
+	for i := range initialAlphas {
+		changed = changed || initialAlphas[i] != trainedAlphas[i]
+	}
+	if !changed {
+		t.Errorf("expected alphas to be learned, got %v", trainedAlphas)
+	}
+}
@@ -0,0 +1,33 @@
+package neuralnet
+
+import "math/rand"
+
+// randomSource is a splitmix64 generator implementing rand.Source64.
+// Unlike the sources of math/rand its whole state is a single uint64,
+// so it can be saved on checkpoints and restored exactly.
+type randomSource struct {
+	state uint64
+}
+
+// NewSource returns a source of randomness seeded with the given seed. Giving
+// it to a network makes its initial weights and its training reproducible,
+// and, unlike other sources, its state is saved on checkpoints.
+func NewSource(seed int64) rand.Source64 {
+	return &randomSource{state: uint64(seed)}
+}
+
+func (s *randomSource) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+func (s *randomSource) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (s *randomSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
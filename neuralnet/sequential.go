@@ -0,0 +1,99 @@
+package neuralnet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+// Sequential stacks layers so the output of one
+// layer is the input of the next one.
+type Sequential struct {
+	layers []Layer
+}
+
+// NewSequential creates a model running the given layers in order.
+func NewSequential(layers ...Layer) (*Sequential, error) {
+	if len(layers) == 0 {
+		return nil, errors.New("at least one layer must be provided")
+	}
+	for i, layer := range layers {
+		if layer == nil {
+			return nil, fmt.Errorf("layer %d is nil", i)
+		}
+	}
+	// the backward process of a softmax output starts from the layer
+	// before it, so a softmax alone would never be trained
+	if _, isSoftmax := layers[0].(*Softmax); isSoftmax && len(layers) == 1 {
+		return nil, errors.New("a softmax layer must follow another layer")
+	}
+	return &Sequential{layers: layers}, nil
+}
+
+func (s *Sequential) Layers() []Layer {
+	return s.layers
+}
+
+// Forward runs X through every layer and returns the output of the last one.
+func (s *Sequential) Forward(X *matrix.Matrix) (*matrix.Matrix, error) {
+	output := X
+	for i, layer := range s.layers {
+		var err error
+		output, err = layer.Forward(output)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run forward on layer %d, got %v", i, err)
+		}
+	}
+	return output, nil
+}
+
+// predict runs X through every layer like Forward, but on new matrices
+// for the layers which support it. Other layers run Forward, so their
+// outputs may be reused and must not be shared between goroutines.
+func (s *Sequential) predict(X *matrix.Matrix) (*matrix.Matrix, error) {
+	output := X
+	for i, layer := range s.layers {
+		var err error
+		if p, ok := layer.(predictor); ok {
+			output, err = p.predict(output)
+		} else {
+			output, err = layer.Forward(output)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to run forward on layer %d, got %v", i, err)
+		}
+	}
+	if _, ok := s.layers[len(s.layers)-1].(predictor); !ok {
+		return output.Copy(), nil
+	}
+	return output, nil
+}
+
+// Backward propagates dE/dY of the last layer down to the first
+// one and returns dE/dX of the whole stack.
+func (s *Sequential) Backward(outputGradient *matrix.Matrix) (*matrix.Matrix, error) {
+	return s.backwardFrom(len(s.layers)-1, outputGradient)
+}
+
+// backwardFrom propagates dE/dY of the layer at index down to the first one.
+func (s *Sequential) backwardFrom(index int, outputGradient *matrix.Matrix) (*matrix.Matrix, error) {
+	gradient := outputGradient
+	for i := index; i >= 0; i-- {
+		var err error
+		gradient, err = s.layers[i].Backward(gradient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run backward on layer %d, got %v", i, err)
+		}
+	}
+	return gradient, nil
+}
+
+// Params returns the parameters of all layers, from the first to the last.
+func (s *Sequential) Params() []*Parameter {
+	var params []*Parameter
+	for _, layer := range s.layers {
+		params = append(params, layer.Params()...)
+	}
+	return params
+}
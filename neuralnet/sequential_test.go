@@ -0,0 +1,81 @@
+package neuralnet_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/activation"
+	"github.com/buarki/supervised-machine-learning/matrix"
+	"github.com/buarki/supervised-machine-learning/neuralnet"
+)
+
+func TestNewSequentialWithoutLayers(t *testing.T) {
+	model, err := neuralnet.NewSequential()
+	if err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+	if model != nil {
+		t.Errorf("expected model to be nil")
+	}
+}
+
+func TestNewSequentialWithOnlySoftmax(t *testing.T) {
+	model, err := neuralnet.NewSequential(neuralnet.NewSoftmax())
+	if err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+	if model != nil {
+		t.Errorf("expected model to be nil")
+	}
+	if _, err := neuralnet.NewWithConfig(neuralnet.Config{Layers: []neuralnet.Layer{neuralnet.NewSoftmax()}}); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+}
+
+func TestSequentialForwardAndBackward(t *testing.T) {
+	random := rand.New(neuralnet.NewSource(1))
+	first, err := neuralnet.NewDense(2, 4, activation.NewSigmoid(), random)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	second, err := neuralnet.NewDense(4, 1, activation.NewSigmoid(), random)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	model, err := neuralnet.NewSequential(first, second)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	X, err := matrix.New(3, 2, []float64{0.3, 1, 0.5, 0.2, 1, 0.4})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	output, err := model.Forward(X)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if output.Rows != 3 || output.Columns != 1 {
+		t.Errorf("expected output to be (3x1), got (%dx%d)", output.Rows, output.Columns)
+	}
+	outputGradient, err := matrix.New(3, 1, []float64{1, 1, 1})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	inputGradient, err := model.Backward(outputGradient)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if inputGradient.Rows != X.Rows || inputGradient.Columns != X.Columns {
+		t.Errorf("expected input gradient to be (%dx%d), got (%dx%d)", X.Rows, X.Columns, inputGradient.Rows, inputGradient.Columns)
+	}
+	params := model.Params()
+	if len(params) != 4 {
+		t.Errorf("expected 4 params, got %d", len(params))
+	}
+	for _, param := range params {
+		if param.Gradient == nil {
+			t.Errorf("expected gradient of %s to be computed", param.Name)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package neuralnet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/buarki/supervised-machine-learning/activation"
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+// Softmax is a layer turning each row of its input into probabilities
+// of classes. It has no params and is meant to be the last layer of a
+// classifier, following a Dense layer using activation.NewIdentity().
+type Softmax struct {
+	y             buffer // probabilities computed on last forward
+	inputGradient buffer // dE/dX computed on last backward
+}
+
+func NewSoftmax() *Softmax {
+	return &Softmax{}
+}
+
+func (s *Softmax) Params() []*Parameter {
+	return nil
+}
+
+// Forward applies softmax to each row of X.
+func (s *Softmax) Forward(X *matrix.Matrix) (*matrix.Matrix, error) {
+	return s.forwardInto(&s.y, X)
+}
+
+// predict runs forward on a new matrix.
+func (s *Softmax) predict(X *matrix.Matrix) (*matrix.Matrix, error) {
+	var y buffer
+	return s.forwardInto(&y, X)
+}
+
+func (s *Softmax) forwardInto(yBuffer *buffer, X *matrix.Matrix) (*matrix.Matrix, error) {
+	if X == nil {
+		return nil, errors.New("param x cannot be nil")
+	}
+	y, err := yBuffer.shaped(X.Rows, X.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create probabilities, got %v", err)
+	}
+	values := X.FlattenedElements()
+	for i := 0; i < X.Rows; i++ {
+		begin, end := i*X.Columns, (i+1)*X.Columns
+		activation.SoftmaxInto(yBuffer.data[begin:end], values[begin:end])
+	}
+	return y, nil
+}
+
+// Backward multiplies dE/dY of each row by the jacobian of softmax,
+// which gives dE/dX_j = Y_j * (dE/dY_j - sum_k dE/dY_k * Y_k).
+func (s *Softmax) Backward(outputGradient *matrix.Matrix) (*matrix.Matrix, error) {
+	if s.y.m == nil {
+		return nil, errors.New("backward called before forward")
+	}
+	if outputGradient.Rows != s.y.m.Rows || outputGradient.Columns != s.y.m.Columns {
+		return nil, fmt.Errorf("expected output gradient to be (%dx%d), received (%dx%d)", s.y.m.Rows, s.y.m.Columns, outputGradient.Rows, outputGradient.Columns)
+	}
+	inputGradient, err := s.inputGradient.shaped(outputGradient.Rows, outputGradient.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create input gradient, got %v", err)
+	}
+	y := s.y.data
+	gradient := outputGradient.FlattenedElements()
+	for i := 0; i < inputGradient.Rows; i++ {
+		begin, end := i*inputGradient.Columns, (i+1)*inputGradient.Columns
+		weightedSum := 0.0
+		for k := begin; k < end; k++ {
+			weightedSum += gradient[k] * y[k]
+		}
+		for j := begin; j < end; j++ {
+			s.inputGradient.data[j] = y[j] * (gradient[j] - weightedSum)
+		}
+	}
+	return inputGradient, nil
+}
@@ -0,0 +1,122 @@
+package neuralnet_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/activation"
+	"github.com/buarki/supervised-machine-learning/matrix"
+	"github.com/buarki/supervised-machine-learning/neuralnet"
+)
+
+func TestSoftmaxForwardGivesRowsSummingToOne(t *testing.T) {
+	X, err := matrix.New(2, 3, []float64{1, 2, 3, -4, 0, 900})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	y, err := neuralnet.NewSoftmax().Forward(X)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	values := y.FlattenedElements()
+	for i := 0; i < y.Rows; i++ {
+		sum := values[i*3] + values[i*3+1] + values[i*3+2]
+		if math.Abs(sum-1) > acceptedError {
+			t.Errorf("expected row %d to sum 1, got %v", i, sum)
+		}
+	}
+}
+
+func TestSoftmaxBackwardMatchesNumericalGradient(t *testing.T) {
+	layer := neuralnet.NewSoftmax()
+	xData := []float64{0.5, -1, 2, 0.1, 0.3, -0.7}
+	// with E = sum of Y*c, dE/dY is c
+	c := []float64{1, -2, 0.5, 3, 0.2, -1}
+	errorOf := func(data []float64) float64 {
+		X, err := matrix.New(2, 3, data)
+		if err != nil {
+			t.Errorf("expected err to be nil, got %v", err)
+		}
+		y, err := layer.Forward(X)
+		if err != nil {
+			t.Errorf("expected err to be nil, got %v", err)
+		}
+		sum := 0.0
+		for i, value := range y.FlattenedElements() {
+			sum += value * c[i]
+		}
+		return sum
+	}
+	outputGradient, err := matrix.New(2, 3, c)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	errorOf(xData)
+	inputGradient, err := layer.Backward(outputGradient)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	smallDifference := 1e-6
+	for i, analytical := range inputGradient.FlattenedElements() {
+		right := append([]float64{}, xData...)
+		right[i] += smallDifference
+		left := append([]float64{}, xData...)
+		left[i] -= smallDifference
+		numerical := (errorOf(right) - errorOf(left)) / (2 * smallDifference)
+		if math.Abs(numerical-analytical) > 1e-6 {
+			t.Errorf("expected input gradient element %d to be %v, got %v", i, numerical, analytical)
+		}
+	}
+}
+
+func TestGradientDescentAccuraceWithSoftmaxOutput(t *testing.T) {
+	X, err := matrix.New(4, 2, []float64{0.3, 1, 0.5, 0.2, 1, 0.4, 0.1, 0.9})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	Y, err := neuralnet.OneHot([]int{0, 2, 1, 0}, 3)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:       2,
+		HiddenLayerSizes:     []int{4},
+		OutputLayerSize:      3,
+		RegularizationFactor: 0.0001,
+		Activation:           activation.NewTanh(),
+		SoftmaxOutput:        true,
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	if name := nn.Loss().Name(); name != "categorical_cross_entropy" {
+		t.Errorf("expected loss to be categorical_cross_entropy, got %s", name)
+	}
+
+	forwardResult, err := nn.PredictForAnalysisBasedOn(X)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	evaluation, err := nn.Evaluate(Y, forwardResult.Prediction())
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	gradientComponents, err := nn.ComputeGradients(Y, evaluation.Error, forwardResult)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	defaultGradients := flattenParams(append(gradientComponents.DEdW, gradientComponents.DEdB...))
+
+	numericalGradients, err := getNumericalGradient(nn, X, Y)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+
+	diff := normOfSlice(subtractArrays(defaultGradients, numericalGradients)) / normOfSlice(sumArrays(defaultGradients, numericalGradients))
+	acceptedError := 1e-4
+	if diff >= acceptedError {
+		t.Errorf("expected diff to be < %v, got %v", acceptedError, diff)
+	}
+}
@@ -1,78 +1,342 @@
 package neuralnet
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"time"
 
 	"github.com/buarki/supervised-machine-learning/matrix"
+	"github.com/buarki/supervised-machine-learning/optimizer"
+	"github.com/buarki/supervised-machine-learning/schedule"
 )
 
+// TrainingData is a batch of samples, one on each row of X
+// along with its expected output on the same row of Y.
 type TrainingData struct {
 	X *matrix.Matrix
 	Y *matrix.Matrix
 }
 
-// Train trains a neural network by injecting data into it
-// while iterating over the epochs.
-func Train(nn *NeuralNet, epochs int, trainingData []TrainingData) error {
-	for epoch := 0; epoch < epochs; epoch++ {
-		log.Printf("starting epoch %d/%d\n", epoch+1, epochs)
-		for trainingDataIndex, data := range trainingData {
-			log.Printf("learning with training data... %d/%d\n", trainingDataIndex+1, len(trainingData))
-			forwardResult, err := nn.PredictForAnalysisBasedOn(data.X)
-			if err != nil {
-				return err
-			}
-			evaluationError, err := nn.Evaluate(data.Y, forwardResult.Y3)
-			if err != nil {
-				return err
-			}
-			gradientComponents, err := nn.ComputeGradients(data.Y, evaluationError.Error, forwardResult)
-			if err != nil {
-				return err
-			}
-			if err != nil {
-				return fmt.Errorf("failed to compute gradients, got %v", err)
-			}
-			newW2, err := computeNewParam(nn.learningRate, nn.W2(), gradientComponents.DEdW2)
-			if err != nil {
-				return fmt.Errorf("failed to compute new W2, got %v", err)
-			}
-			newW3, err := computeNewParam(nn.learningRate, nn.W3(), gradientComponents.DEdW3)
-			if err != nil {
-				return fmt.Errorf("failed to compute new W3, got %v", err)
-			}
-			if err := nn.AdjustWeights(newW2, newW3); err != nil {
-				return fmt.Errorf("failed to adjust weights during train, got %v", err)
-			}
-			newB2, err := computeNewParam(nn.learningRate, nn.B2(), gradientComponents.DEdB2)
-			if err != nil {
-				return fmt.Errorf("failed to compute new B2, got %v", err)
-			}
-			newB3, err := computeNewParam(nn.learningRate, nn.B3(), gradientComponents.DEdB3)
-			if err != nil {
-				return fmt.Errorf("failed to compute new B3, got %v", err)
-			}
-			if err := nn.AdjustBiases(newB2, newB3); err != nil {
-				return fmt.Errorf("failed to adjust biases during train, got %v", err)
-			}
-			log.Printf("learned using data %d/%d, got error %.7f\n", trainingDataIndex+1, len(trainingData), evaluationError.ErrorCost)
-		}
-		log.Printf("finished epoch %d\n", epoch+1)
+// trainingState is what the training loop needs to continue
+// from where it stopped, and so it is saved on checkpoints.
+type trainingState struct {
+	epoch      int              // Epochs already finished
+	step       int              // Batches already learned
+	bestLoss   float64          // Lowest monitored loss seen so far
+	bestEpoch  int              // Epochs finished when bestLoss was seen
+	bestParams []*matrix.Matrix // Params when bestLoss was seen, only kept for early stopping
+}
+
+// Train trains a neural network by injecting data into it while
+// iterating over the epochs. Progress is only logged when a
+// ProgressLogger is given with WithCallbacks, interruptions and
+// stops aside. Each training data is learned as a batch
+// unless WithBatchSize is given, in which case their rows are taken as
+// a flat list of samples that is shuffled and batched on every epoch.
+// The monitored loss is the validation one when WithValidation is
+// given and the training one otherwise. The history of the finished
+// epochs is returned even when the train fails.
+func Train(nn *NeuralNet, epochs int, trainingData []TrainingData, options ...TrainOption) (*History, error) {
+	return TrainContext(context.Background(), nn, epochs, trainingData, options...)
+}
+
+// TrainContext is Train stopping once ctx is done. Cancellation and
+// deadlines are checked before each batch, so the network is left with
+// the params learned from the last batch and can be saved. The returned
+// *InterruptedError tells how far the train went and unwraps to ctx.Err().
+func TrainContext(ctx context.Context, nn *NeuralNet, epochs int, trainingData []TrainingData, options ...TrainOption) (*History, error) {
+	config, err := newTrainConfig(nn, options)
+	if err != nil {
+		return nil, err
+	}
+	return nn.train(ctx, &trainingState{bestLoss: math.Inf(1)}, epochs, trainingData, config)
+}
+
+// InterruptedError is returned when the context given to TrainContext
+// or ResumeContext is done before the train ends.
+type InterruptedError struct {
+	Epoch    int     // Epochs finished
+	Step     int     // Batches learned
+	BestLoss float64 // Lowest monitored loss seen, +Inf if none
+	Err      error   // Error of the context
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("training interrupted after %d epochs and %d steps, got %v", e.Epoch, e.Step, e.Err)
+}
+
+func (e *InterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// interrupted returns an *InterruptedError when ctx is done.
+func interrupted(ctx context.Context, state *trainingState) error {
+	if err := ctx.Err(); err != nil {
+		log.Printf("interrupting train after epoch %d and step %d, got %v\n", state.epoch, state.step, err)
+		return &InterruptedError{Epoch: state.epoch, Step: state.step, BestLoss: state.bestLoss, Err: err}
+	}
+	return nil
+}
+
+func (nn *NeuralNet) train(ctx context.Context, state *trainingState, epochs int, trainingData []TrainingData, config *trainConfig) (*History, error) {
+	flat, history, err := nn.startTrain(trainingData, config)
+	if err != nil {
+		return nil, err
+	}
+	stopped, err := stopRequested(config.callbacks.notify(Callback.OnTrainBegin, nn.eventFor(config, state, epochs)), "train begin")
+	if err != nil {
+		return history, err
+	}
+	lastCheckpoint := time.Now()
+	for !stopped && state.epoch < epochs {
+		stopped, err = nn.trainEpoch(ctx, state, epochs, trainingData, flat, config, &lastCheckpoint)
+		if err != nil {
+			return history, err
+		}
+	}
+	if config.earlyStopping != nil && state.bestParams != nil {
+		if err := adjust(nn.Params(), state.bestParams); err != nil {
+			return history, fmt.Errorf("failed to restore best params, got %v", err)
+		}
+	}
+	_, err = stopRequested(config.callbacks.notify(Callback.OnTrainEnd, nn.eventFor(config, state, epochs)), "train end")
+	return history, err
+}
+
+// startTrain reads the samples batched on every epoch, if any, and adds
+// the callback recording the returned history.
+func (nn *NeuralNet) startTrain(trainingData []TrainingData, config *trainConfig) (*samples, *History, error) {
+	if len(trainingData) == 0 {
+		return nil, nil, fmt.Errorf("training data cannot be empty")
+	}
+	var flat *samples
+	if config.batch.size > 0 {
+		var err error
+		flat, err = newSamples(trainingData)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read samples, got %v", err)
+		}
+	}
+	history := &History{}
+	// the history is recorded first, so it has the epochs other callbacks stop on
+	config.callbacks = append(callbacks{&historyRecorder{history: history}}, config.callbacks...)
+	return flat, history, nil
+}
+
+// trainEpoch learns every batch of the epoch, validates the network when due
+// and writes a checkpoint when due. It returns whether the train must stop,
+// either by early stopping or by a callback.
+func (nn *NeuralNet) trainEpoch(ctx context.Context, state *trainingState, epochs int, trainingData []TrainingData, flat *samples, config *trainConfig, lastCheckpoint *time.Time) (bool, error) {
+	epoch := state.epoch
+	if stop, err := stopRequested(config.callbacks.notify(Callback.OnEpochBegin, nn.eventFor(config, state, epochs)), "epoch begin"); stop || err != nil {
+		return stop, err
+	}
+	batches, err := nn.batchesFor(trainingData, flat, config.batch)
+	if err != nil {
+		return false, err
+	}
+	var learningRate, epochErrorCost float64
+	// the expected outputs and predictions of the epoch, kept to measure metrics
+	var expected, predicted []float64
+	samples := 0
+	for batchIndex, data := range batches {
+		if err := interrupted(ctx, state); err != nil {
+			return false, err
+		}
+		learningRate = config.schedule.LearningRate(epoch, state.step)
+		errorCost, gradients, err := nn.trainStep(learningRate, data)
+		if err != nil {
+			return false, err
+		}
+		// a partial last batch counts as much as its samples
+		epochErrorCost += errorCost * float64(data.X.Rows)
+		samples += data.X.Rows
+		state.step++
+		if len(config.metrics) > 0 {
+			expected = append(expected, data.Y.FlattenedElements()...)
+			predicted = append(predicted, nn.workspace.prediction.FlattenedElements()...)
+		}
+		event := nn.eventFor(config, state, epochs)
+		event.Epoch, event.Batch, event.Batches = epoch, batchIndex, len(batches)
+		event.LearningRate, event.Loss, event.Gradients = learningRate, errorCost, gradients
+		if stop, err := stopRequested(config.callbacks.notify(Callback.OnBatchEnd, event), "batch end"); stop || err != nil {
+			return stop, err
+		}
+	}
+	epochErrorCost /= float64(samples)
+	state.epoch++
+	var trainMetrics map[string]float64
+	if len(config.metrics) > 0 {
+		trainMetrics, err = measure(config.metrics, samples, batches[0].Y.Columns, expected, predicted)
+		if err != nil {
+			return false, fmt.Errorf("failed to measure epoch %d, got %v", state.epoch, err)
+		}
+	}
+	var validation *Validation
+	monitored, monitoring := epochErrorCost, len(config.validation.data) == 0
+	if config.validation.isDue(state.epoch) {
+		validation, err = nn.Validate(config.validation.data, config.metrics...)
+		if err != nil {
+			return false, fmt.Errorf("failed to validate after epoch %d, got %v", state.epoch, err)
+		}
+		monitored, monitoring = validation.Loss, true
+	}
+	stop := false
+	if monitoring {
+		if observer, ok := config.schedule.(schedule.Observer); ok {
+			observer.Observe(monitored)
+		}
+		if err := nn.monitor(state, monitored, config.earlyStopping); err != nil {
+			return false, err
+		}
+		stop = config.earlyStopping != nil && state.epoch-state.bestEpoch >= config.earlyStopping.patience
+		if stop {
+			log.Printf("stopping early after epoch %d, best loss %.7f was seen on epoch %d\n", state.epoch, state.bestLoss, state.bestEpoch)
+		}
+	}
+	event := nn.eventFor(config, state, epochs)
+	event.Epoch, event.LearningRate, event.Loss, event.Validation = epoch, learningRate, epochErrorCost, validation
+	event.TrainMetrics = trainMetrics
+	callbackStop, err := stopRequested(config.callbacks.notify(Callback.OnEpochEnd, event), "epoch end")
+	if err != nil {
+		return false, err
+	}
+	if config.checkpoint.isDue(state.epoch, time.Since(*lastCheckpoint)) {
+		if err := nn.saveCheckpoint(config.checkpoint.path, state, config); err != nil {
+			return false, fmt.Errorf("failed to save checkpoint after epoch %d, got %v", state.epoch, err)
+		}
+		*lastCheckpoint = time.Now()
+	}
+	return stop || callbackStop, nil
+}
+
+// eventFor returns the event of the current point of the train. It is
+// the same event every time, so notifying callbacks doesn't allocate.
+func (nn *NeuralNet) eventFor(config *trainConfig, state *trainingState, epochs int) *TrainEvent {
+	config.event = TrainEvent{Model: nn, Epoch: state.epoch, Epochs: epochs, Step: state.step}
+	return &config.event
+}
+
+// stopRequested tells whether the error returned by a callback asks to
+// stop the train. Any other error is returned to make the train fail.
+func stopRequested(err error, hook string) (bool, error) {
+	if err == nil {
+		return false, nil
+	}
+	if errors.Is(err, ErrStopTraining) {
+		log.Printf("stopping as asked by callback on %s\n", hook)
+		return true, nil
+	}
+	return false, fmt.Errorf("callback failed on %s, got %v", hook, err)
+}
+
+// monitor keeps loss as the best one when it is lower than the best by
+// more than the early stopping min delta, along with the params if they
+// are restored by early stopping.
+func (nn *NeuralNet) monitor(state *trainingState, loss float64, earlyStopping *earlyStoppingConfig) error {
+	minDelta := 0.0
+	if earlyStopping != nil {
+		minDelta = earlyStopping.minDelta
+	}
+	if !(loss < state.bestLoss-minDelta) {
+		return nil
+	}
+	state.bestLoss, state.bestEpoch = loss, state.epoch
+	if earlyStopping != nil {
+		params, err := nn.copyParams()
+		if err != nil {
+			return fmt.Errorf("failed to keep best params, got %v", err)
+		}
+		state.bestParams = params
 	}
 	return nil
 }
 
-func computeNewParam(learningRate float64, oldParam, paramGradientComponent *matrix.Matrix) (*matrix.Matrix, error) {
-	paramGradientComponentTimesLearninRate, err := paramGradientComponent.ApplyElementWise(func(value float64) float64 {
-		return value * learningRate
-	})
+// trainWorkspace keeps what train steps compute besides the matrices of
+// the layers, so the steps after the first one reuse it.
+type trainWorkspace struct {
+	params         []*Parameter     // Params of the network, in the order of NeuralNet.Params
+	values         []*matrix.Matrix // Value of each param, given to the optimizer
+	gradients      []buffer         // dE/dParam of each param, averaged and regularized
+	gradientValues []*matrix.Matrix // Matrices of gradients, returned by trainStep
+	prediction     *matrix.Matrix   // Output of the network on the last batch, before learning it
+	outputGradient buffer           // dE/dY of the output layer
+}
+
+// prepare keeps the params of nn on the workspace the first time it is called.
+func (w *trainWorkspace) prepare(nn *NeuralNet) {
+	if w.params != nil {
+		return
+	}
+	w.params = nn.Params()
+	w.values = make([]*matrix.Matrix, len(w.params))
+	w.gradients = make([]buffer, len(w.params))
+	w.gradientValues = make([]*matrix.Matrix, len(w.params))
+}
+
+// trainStep runs the forward and backward process on a batch, updates the
+// network params and returns the error cost of the batch along with the
+// gradient of each param. Matrices are reused from step to step, so once
+// the first step is done the next ones on batches of the same size don't
+// allocate, as long as the loss and the optimizer support it.
+func (nn *NeuralNet) trainStep(learningRate float64, data TrainingData) (float64, []*matrix.Matrix, error) {
+	if data.X == nil {
+		return 0, nil, errors.New("param x cannot be nil")
+	}
+	workspace := &nn.workspace
+	workspace.prepare(nn)
+	prediction, err := nn.model.Forward(data.X)
 	if err != nil {
-		return nil, fmt.Errorf("failed to compute learning rate * weight, got %v", err)
+		return 0, nil, err
 	}
-	newParam, err := oldParam.Minus(paramGradientComponentTimesLearninRate)
+	workspace.prediction = prediction
+	errorCost, err := nn.computeErrorCost(workspace.params, data.Y, prediction)
 	if err != nil {
-		return nil, fmt.Errorf("failed to compute weight - (learningRate*weight), got %v", err)
+		return 0, nil, err
+	}
+	if err := nn.backward(data.Y, prediction); err != nil {
+		return 0, nil, fmt.Errorf("failed to compute gradients, got %v", err)
+	}
+	for i, param := range workspace.params {
+		gradient, err := workspace.gradients[i].shaped(param.Value.Rows, param.Value.Columns)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create gradient of param %d (%s), got %v", i, param.Name, err)
+		}
+		// gradients are averaged over the samples actually given
+		if err := nn.computeGradientInto(gradient, param, data.X.Rows); err != nil {
+			return 0, nil, fmt.Errorf("failed to compute gradient of param %d (%s), got %v", i, param.Name, err)
+		}
+		workspace.gradientValues[i] = gradient
 	}
-	return newParam, nil
+	if err := nn.updateParams(learningRate, workspace.gradientValues); err != nil {
+		return 0, nil, err
+	}
+	return errorCost, workspace.gradientValues, nil
+}
+
+// updateParams gives every param along with its gradient to the optimizer.
+// Optimizers implementing optimizer.InPlace change the params, while the
+// network is adjusted with the params returned by the other ones.
+func (nn *NeuralNet) updateParams(learningRate float64, gradients []*matrix.Matrix) error {
+	params := nn.workspace.params
+	if inPlace, ok := nn.optimizer.(optimizer.InPlace); ok {
+		for i, param := range params {
+			nn.workspace.values[i] = param.Value
+		}
+		if err := inPlace.UpdateInPlace(learningRate, nn.workspace.values, gradients); err != nil {
+			return fmt.Errorf("failed to update params, got %v", err)
+		}
+		return nil
+	}
+	newParams, err := nn.optimizer.Update(learningRate, values(params), gradients)
+	if err != nil {
+		return fmt.Errorf("failed to compute new params, got %v", err)
+	}
+	if err := adjust(params, newParams); err != nil {
+		return fmt.Errorf("failed to adjust params during train, got %v", err)
+	}
+	return nil
 }
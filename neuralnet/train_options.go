@@ -0,0 +1,169 @@
+package neuralnet
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/buarki/supervised-machine-learning/metric"
+	"github.com/buarki/supervised-machine-learning/schedule"
+)
+
+// TrainOption customizes how Train runs.
+type TrainOption func(*trainConfig)
+
+type trainConfig struct {
+	schedule   schedule.Schedule
+	checkpoint checkpointConfig
+	batch      batchConfig
+	validation validationConfig
+	// metrics are measured on the training batches and on validations
+	metrics []metric.Metric
+	// earlyStopping is nil unless WithEarlyStopping is given
+	earlyStopping *earlyStoppingConfig
+	callbacks     callbacks
+	// event is given to every notification of callbacks
+	event TrainEvent
+}
+
+// checkpointConfig tells when checkpoints are written. A checkpoint
+// is due every everyEpochs epochs or once interval has passed since
+// the last one, whichever comes first.
+type checkpointConfig struct {
+	path        string
+	everyEpochs int
+	interval    time.Duration
+}
+
+func (c checkpointConfig) isDue(finishedEpochs int, sinceLast time.Duration) bool {
+	if c.path == "" {
+		return false
+	}
+	return (c.everyEpochs > 0 && finishedEpochs%c.everyEpochs == 0) || (c.interval > 0 && sinceLast >= c.interval)
+}
+
+func newTrainConfig(nn *NeuralNet, options []TrainOption) (*trainConfig, error) {
+	config := &trainConfig{schedule: constantRate(nn.learningRate)}
+	for _, option := range options {
+		option(config)
+	}
+	if config.schedule == nil {
+		return nil, fmt.Errorf("learning rate schedule cannot be nil")
+	}
+	if config.checkpoint.everyEpochs < 0 || config.checkpoint.interval < 0 {
+		return nil, fmt.Errorf("checkpoint frequency must be >= 0")
+	}
+	if len(config.validation.data) > 0 && config.validation.everyEpochs <= 0 {
+		return nil, fmt.Errorf("validation frequency must be > 0, received %d", config.validation.everyEpochs)
+	}
+	if config.earlyStopping != nil && (config.earlyStopping.patience <= 0 || config.earlyStopping.minDelta < 0) {
+		return nil, fmt.Errorf("early stopping patience must be > 0 and min delta >= 0, received %d and %v", config.earlyStopping.patience, config.earlyStopping.minDelta)
+	}
+	for i, callback := range config.callbacks {
+		if callback == nil {
+			return nil, fmt.Errorf("callback %d cannot be nil", i)
+		}
+	}
+	if config.batch.size < 0 {
+		return nil, fmt.Errorf("batch size must be >= 0, received %d", config.batch.size)
+	}
+	return config, nil
+}
+
+// WithSchedule makes Train ask the given schedule for the learning rate of
+// each step instead of using the network learning rate. If the schedule is
+// a schedule.Observer it is given the loss of every epoch, or with
+// WithValidation the validation loss of every epoch validated on.
+func WithSchedule(s schedule.Schedule) TrainOption {
+	return func(config *trainConfig) {
+		config.schedule = s
+	}
+}
+
+// WithCheckpoint makes Train write a checkpoint on path every
+// everyEpochs epochs, which Resume can continue from.
+func WithCheckpoint(path string, everyEpochs int) TrainOption {
+	return func(config *trainConfig) {
+		config.checkpoint.path = path
+		config.checkpoint.everyEpochs = everyEpochs
+	}
+}
+
+// WithCheckpointInterval makes Train write a checkpoint on path at the end
+// of the first epoch finished once interval has passed since the last one.
+// It can be combined with WithCheckpoint using the same path.
+func WithCheckpointInterval(path string, interval time.Duration) TrainOption {
+	return func(config *trainConfig) {
+		config.checkpoint.path = path
+		config.checkpoint.interval = interval
+	}
+}
+
+// WithBatchSize makes Train learn from batches of size samples taken
+// from the rows of all training data, shuffled on every epoch with the
+// network source, so WithSeed gives the same batches on every run.
+// The last batch has the remaining samples, see WithDropLast.
+func WithBatchSize(size int) TrainOption {
+	return func(config *trainConfig) {
+		config.batch.size = size
+	}
+}
+
+// WithDropLast makes Train skip the last batch of an epoch when it
+// has less samples than the batch size given by WithBatchSize.
+func WithDropLast() TrainOption {
+	return func(config *trainConfig) {
+		config.batch.dropLast = true
+	}
+}
+
+// WithoutShuffle makes Train batch the samples given by WithBatchSize
+// in the order they are given on every epoch.
+func WithoutShuffle() TrainOption {
+	return func(config *trainConfig) {
+		config.batch.noShuffle = true
+	}
+}
+
+// WithValidation makes Train validate the network on data every
+// everyEpochs epochs, logging the loss and the metrics given by
+// WithMetrics. The validation loss is then the one monitored by
+// WithEarlyStopping instead of the training loss.
+func WithValidation(data []TrainingData, everyEpochs int) TrainOption {
+	return func(config *trainConfig) {
+		config.validation.data = data
+		config.validation.everyEpochs = everyEpochs
+	}
+}
+
+// WithMetrics sets the metrics measured on the training batches of
+// every epoch, on the predictions made before learning each batch, and
+// on every validation.
+func WithMetrics(metrics ...metric.Metric) TrainOption {
+	return func(config *trainConfig) {
+		config.metrics = metrics
+	}
+}
+
+// WithEarlyStopping makes Train stop once the monitored loss hasn't
+// decreased by more than minDelta for patience epochs, and then
+// restore the params that had the lowest loss.
+func WithEarlyStopping(patience int, minDelta float64) TrainOption {
+	return func(config *trainConfig) {
+		config.earlyStopping = &earlyStoppingConfig{patience: patience, minDelta: minDelta}
+	}
+}
+
+// WithCallbacks makes Train notify the given callbacks, in
+// order, along the train.
+func WithCallbacks(c ...Callback) TrainOption {
+	return func(config *trainConfig) {
+		config.callbacks = append(config.callbacks, c...)
+	}
+}
+
+// constantRate is the schedule used when none is given.
+type constantRate float64
+
+func (r constantRate) LearningRate(epoch, step int) float64 {
+	return float64(r)
+}
@@ -1,25 +1,531 @@
 package neuralnet_test
 
 import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"time"
+
 	"testing"
 
 	"github.com/buarki/supervised-machine-learning/activation"
+	"github.com/buarki/supervised-machine-learning/initializer"
+	"github.com/buarki/supervised-machine-learning/matrix"
 	"github.com/buarki/supervised-machine-learning/neuralnet"
+	"github.com/buarki/supervised-machine-learning/optimizer"
 	"github.com/buarki/supervised-machine-learning/sample"
 )
 
 func TestTrain(t *testing.T) {
-	nn, err := neuralnet.New(0.001, 0.0001, activation.Sigmoid, activation.SigmoidPrime)
+	nn, err := neuralnet.New(neuralnet.WithLearningRate(0.001), neuralnet.WithRegularizer(0.0001))
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+
+	sample, err := sample.GetAReadyInputAndOutputSample()
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+
+	if _, err := neuralnet.Train(nn, 1, []neuralnet.TrainingData{{X: sample.Input, Y: sample.Output}}); err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+}
+
+func TestTrainWithOptimizer(t *testing.T) {
+	adam, err := optimizer.NewAdam(0.9, 0.999, 1e-8)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:   2,
+		HiddenLayerSizes: []int{3},
+		OutputLayerSize:  1,
+		LearningRate:     0.05,
+		Activation:       activation.NewSigmoid(),
+		Optimizer:        adam,
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	X, err := matrix.New(3, 2, []float64{0.3, 1, 0.5, 0.2, 1, 0.4})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	Y, err := matrix.New(3, 1, []float64{0.75, 0.82, 0.93})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+
+	if _, err := neuralnet.Train(nn, 1000, []neuralnet.TrainingData{{X: X, Y: Y}}); err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+
+	prediction, err := nn.PredictBasedOn(X)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	evaluation, err := nn.Evaluate(Y, prediction)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	if evaluation.ErrorCost > 1e-3 {
+		t.Errorf("expected error cost to be <= 1e-3 after training, got %v", evaluation.ErrorCost)
+	}
+}
+
+type recordingSchedule struct {
+	calls    [][2]int
+	observed []float64
+}
+
+func (s *recordingSchedule) LearningRate(epoch, step int) float64 {
+	s.calls = append(s.calls, [2]int{epoch, step})
+	return 0.01
+}
+
+func (s *recordingSchedule) Observe(loss float64) {
+	s.observed = append(s.observed, loss)
+}
+
+func TestTrainWithSchedule(t *testing.T) {
+	nn, err := neuralnet.New(neuralnet.WithLearningRate(0.001), neuralnet.WithRegularizer(0.0001))
 	if err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
 	}
+	sample, err := sample.GetAReadyInputAndOutputSample()
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	data := neuralnet.TrainingData{X: sample.Input, Y: sample.Output}
+	s := &recordingSchedule{}
+
+	if _, err := neuralnet.Train(nn, 2, []neuralnet.TrainingData{data, data}, neuralnet.WithSchedule(s)); err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+
+	expectedCalls := [][2]int{{0, 0}, {0, 1}, {1, 2}, {1, 3}}
+	if len(s.calls) != len(expectedCalls) {
+		t.Fatalf("expected schedule to be called %d times, got %d", len(expectedCalls), len(s.calls))
+	}
+	for i, call := range s.calls {
+		if call != expectedCalls[i] {
+			t.Errorf("expected call %d to be (epoch, step) %v, got %v", i, expectedCalls[i], call)
+		}
+	}
+	if len(s.observed) != 2 {
+		t.Errorf("expected schedule to observe the loss of 2 epochs, got %d", len(s.observed))
+	}
+}
 
+func trainSeeded(t *testing.T, seed int64) *neuralnet.NeuralNet {
+	adam, err := optimizer.NewAdam(0.9, 0.999, 1e-8)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	nn, err := neuralnet.NewWithConfig(neuralnet.Config{
+		InputLayerSize:   2,
+		HiddenLayerSizes: []int{4, 3},
+		OutputLayerSize:  1,
+		LearningRate:     0.01,
+		Activation:       activation.NewTanh(),
+		Optimizer:        adam,
+		Source:           neuralnet.NewSource(seed),
+	})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
 	sample, err := sample.GetAReadyInputAndOutputSample()
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if _, err := neuralnet.Train(nn, 20, []neuralnet.TrainingData{{X: sample.Input, Y: sample.Output}}); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	return nn
+}
+
+func TestTrainWithSameSeedIsReproducible(t *testing.T) {
+	first := trainSeeded(t, 42)
+	second := trainSeeded(t, 42)
+
+	for i, w := range first.Weights() {
+		ensureMatricesAreEqual(t, second.Weights()[i], w)
+	}
+	for i, b := range first.Biases() {
+		ensureMatricesAreEqual(t, second.Biases()[i], b)
+	}
+}
+
+func TestTrainWithDifferentSeeds(t *testing.T) {
+	first := trainSeeded(t, 42)
+	second := trainSeeded(t, 43)
+
+	diff, err := first.Weights()[0].FrobeniusNormRatio(second.Weights()[0])
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	if diff == 0 {
+		t.Errorf("expected networks seeded differently to have different weights")
+	}
+}
+
+func newSamples(t *testing.T, count int) neuralnet.TrainingData {
+	x := make([]float64, 0, 2*count)
+	y := make([]float64, 0, count)
+	for i := 0; i < count; i++ {
+		x = append(x, float64(i)/float64(count), float64(count-i)/float64(count))
+		y = append(y, float64(i%2))
+	}
+	X, err := matrix.New(count, 2, x)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	Y, err := matrix.New(count, 1, y)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	return neuralnet.TrainingData{X: X, Y: Y}
+}
+
+func TestTrainWithBatchSize(t *testing.T) {
+	testCases := []struct {
+		options         []neuralnet.TrainOption
+		expectedBatches int
+	}{
+		{options: []neuralnet.TrainOption{neuralnet.WithBatchSize(4)}, expectedBatches: 3},
+		{options: []neuralnet.TrainOption{neuralnet.WithBatchSize(4), neuralnet.WithDropLast()}, expectedBatches: 2},
+		{options: []neuralnet.TrainOption{neuralnet.WithBatchSize(5), neuralnet.WithDropLast()}, expectedBatches: 2},
+		{options: []neuralnet.TrainOption{neuralnet.WithBatchSize(20)}, expectedBatches: 1},
+	}
+	for _, testCase := range testCases {
+		nn, err := neuralnet.New(neuralnet.WithSeed(1))
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		s := &recordingSchedule{}
+		options := append(testCase.options, neuralnet.WithSchedule(s))
+
+		// the 10 samples of both training data are batched together
+		data := []neuralnet.TrainingData{newSamples(t, 6), newSamples(t, 4)}
+		if _, err := neuralnet.Train(nn, 2, data, options...); err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+		}
+		if len(s.calls) != 2*testCase.expectedBatches {
+			t.Errorf("expected %d batches on each epoch, got %d steps in 2 epochs", testCase.expectedBatches, len(s.calls))
+		}
+	}
+}
+
+func TestTrainWeightsEpochLossBySamples(t *testing.T) {
+	// params don't change, so every prediction stays sigmoid(0) = 0.5
+	nn, err := neuralnet.New(
+		neuralnet.WithLayers(2, 1),
+		neuralnet.WithLearningRate(0),
+		neuralnet.WithInitializer(initializer.NewZeros()),
+		neuralnet.WithSeed(1),
+	)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	X, err := matrix.New(3, 2, []float64{1, 1, 2, 2, 3, 3})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	Y, err := matrix.New(3, 1, []float64{0.5, 0.5, 2.5})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	// the losses are 0 and 0 on the first batch and 2 on the partial last one
+	history, err := neuralnet.Train(nn, 1, []neuralnet.TrainingData{{X: X, Y: Y}}, neuralnet.WithBatchSize(2), neuralnet.WithoutShuffle())
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if expected := 2.0 / 3.0; math.Abs(history.Epochs[0].Loss-expected) > 1e-9 {
+		t.Errorf("expected epoch loss to be %v, got %v", expected, history.Epochs[0].Loss)
+	}
+}
+
+func trainInBatches(t *testing.T, seed int64, options ...neuralnet.TrainOption) *neuralnet.NeuralNet {
+	nn, err := neuralnet.New(neuralnet.WithLearningRate(0.1), neuralnet.WithSeed(seed))
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	options = append(options, neuralnet.WithBatchSize(3))
+	if _, err := neuralnet.Train(nn, 5, []neuralnet.TrainingData{newSamples(t, 10)}, options...); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	return nn
+}
+
+func TestTrainWithBatchSizeShufflesReproducibly(t *testing.T) {
+	first := trainInBatches(t, 7)
+	second := trainInBatches(t, 7)
+	for i, w := range first.Weights() {
+		ensureMatricesAreEqual(t, second.Weights()[i], w)
+	}
+
+	notShuffled := trainInBatches(t, 7, neuralnet.WithoutShuffle())
+	diff, err := first.Weights()[0].FrobeniusNormRatio(notShuffled.Weights()[0])
 	if err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
 	}
+	if diff == 0 {
+		t.Errorf("expected shuffled samples to give different weights")
+	}
+}
 
-	if err := neuralnet.Train(nn, 1, []neuralnet.TrainingData{{X: sample.Input, Y: sample.Output}}); err != nil {
+func TestTrainWithoutShuffleKeepsSamplesOrder(t *testing.T) {
+	data := newSamples(t, 6)
+	batched, err := neuralnet.New(neuralnet.WithSeed(3))
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if _, err := neuralnet.Train(batched, 3, []neuralnet.TrainingData{data}, neuralnet.WithBatchSize(6), neuralnet.WithoutShuffle()); err != nil {
 		t.Errorf("expected error to be nil, got %v", err)
 	}
+	whole, err := neuralnet.New(neuralnet.WithSeed(3))
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if _, err := neuralnet.Train(whole, 3, []neuralnet.TrainingData{data}); err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	for i, w := range whole.Weights() {
+		ensureMatricesAreEqual(t, batched.Weights()[i], w)
+	}
+}
+
+func TestTrainWithInvalidBatches(t *testing.T) {
+	testCases := [][]neuralnet.TrainOption{
+		{neuralnet.WithBatchSize(-1)},
+		{neuralnet.WithBatchSize(11), neuralnet.WithDropLast()},
+	}
+	for _, options := range testCases {
+		nn, err := neuralnet.New()
+		if err != nil {
+			t.Fatalf("expected error to be nil, got %v", err)
+		}
+		if _, err := neuralnet.Train(nn, 1, []neuralnet.TrainingData{newSamples(t, 10)}, options...); err == nil {
+			t.Errorf("expected error to be not nil")
+		}
+	}
+
+	nn, err := neuralnet.New()
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	mismatched := []neuralnet.TrainingData{newSamples(t, 2), {X: newSamples(t, 2).X, Y: newSamples(t, 3).Y}}
+	if _, err := neuralnet.Train(nn, 1, mismatched, neuralnet.WithBatchSize(2)); err == nil {
+		t.Errorf("expected error to be not nil")
+	}
+}
+
+// cancelingCallback cancels the train context once it learned steps batches.
+type cancelingCallback struct {
+	neuralnet.BaseCallback
+	cancel context.CancelFunc
+	steps  int
+}
+
+func (c *cancelingCallback) OnBatchEnd(event *neuralnet.TrainEvent) error {
+	if event.Step == c.steps {
+		c.cancel()
+	}
+	return nil
+}
+
+func TestTrainContextStopsWhenCanceled(t *testing.T) {
+	nn := newConstantNeuralNet(t)
+	training, _ := divergingData(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := neuralnet.TrainContext(ctx, nn, 10, append(training, training...), neuralnet.WithCallbacks(&cancelingCallback{cancel: cancel, steps: 3}))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected err to be context canceled, got %v", err)
+	}
+	var interrupted *neuralnet.InterruptedError
+	if !errors.As(err, &interrupted) {
+		t.Fatalf("expected err to be an interrupted error, got %v", err)
+	}
+	if interrupted.Epoch != 1 || interrupted.Step != 3 {
+		t.Errorf("expected train to stop on epoch 1 and step 3, got %d and %d", interrupted.Epoch, interrupted.Step)
+	}
+
+	// the interrupted network is the one trained on the first 3 batches
+	expected := newConstantNeuralNet(t)
+	if _, err := neuralnet.Train(expected, 1, append(training, training[0], training[0])); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	for i, w := range expected.Weights() {
+		ensureMatricesAreEqual(t, nn.Weights()[i], w)
+	}
+	path := filepath.Join(t.TempDir(), "model.json")
+	if err := nn.Save(path); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if _, err := neuralnet.Load(path); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+}
+
+func TestTrainContextWithExceededDeadline(t *testing.T) {
+	nn := newConstantNeuralNet(t)
+	training, _ := divergingData(t)
+	// params are trained in place, so the initial ones are copied
+	var initialWeights []*matrix.Matrix
+	for _, w := range nn.Weights() {
+		initialWeights = append(initialWeights, w.Copy())
+	}
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	_, err := neuralnet.TrainContext(ctx, nn, 10, training)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected err to be deadline exceeded, got %v", err)
+	}
+	for i, w := range initialWeights {
+		ensureMatricesAreEqual(t, nn.Weights()[i], w)
+	}
+}
+
+func TestTrainStepDoesNotAllocateAfterTheFirstOne(t *testing.T) {
+	adam, err := optimizer.NewAdam(0.9, 0.999, 1e-8)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	labels, err := neuralnet.OneHot([]int{0, 1, 2, 0, 1, 2, 0, 1}, 3)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	testCases := []struct {
+		name    string
+		options []neuralnet.Option
+		Y       *matrix.Matrix
+	}{
+		{
+			name:    "regression",
+			options: []neuralnet.Option{neuralnet.WithRegularizer(0.001)},
+			Y:       newSamples(t, 8).Y,
+		},
+		{
+			name: "classification",
+			options: []neuralnet.Option{
+				neuralnet.WithLayers(2, 16, 3),
+				neuralnet.WithActivation(activation.NewReLU()),
+				neuralnet.WithSoftmaxOutput(),
+				neuralnet.WithOptimizer(adam),
+			},
+			Y: labels,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nn, err := neuralnet.New(append(tc.options, neuralnet.WithSeed(1))...)
+			if err != nil {
+				t.Fatalf("expected error to be nil, got %v", err)
+			}
+			data := neuralnet.TrainingData{X: newSamples(t, 8).X, Y: tc.Y}
+			if _, _, err := neuralnet.TrainStep(nn, 0.01, data); err != nil {
+				t.Fatalf("expected error to be nil, got %v", err)
+			}
+
+			allocations := testing.AllocsPerRun(10, func() {
+				if _, _, err := neuralnet.TrainStep(nn, 0.01, data); err != nil {
+					t.Fatalf("expected error to be nil, got %v", err)
+				}
+			})
+			if allocations != 0 {
+				t.Errorf("expected train step not to allocate, got %v allocations", allocations)
+			}
+
+			// predictions of another size, as on validation, don't
+			// reshape the matrices train steps reuse
+			X := newSamples(t, 3).X
+			predict := func() {
+				if _, err := nn.PredictBasedOn(X); err != nil {
+					t.Fatalf("expected error to be nil, got %v", err)
+				}
+			}
+			predictAllocations := testing.AllocsPerRun(10, predict)
+			allocations = testing.AllocsPerRun(10, func() {
+				if _, _, err := neuralnet.TrainStep(nn, 0.01, data); err != nil {
+					t.Fatalf("expected error to be nil, got %v", err)
+				}
+				predict()
+			})
+			if allocations != predictAllocations {
+				t.Errorf("expected train step not to allocate between predictions, got %v allocations instead of %v", allocations, predictAllocations)
+			}
+		})
+	}
+}
+
+func TestTrainEpochDoesNotAllocateAfterTheFirstOne(t *testing.T) {
+	adam, err := optimizer.NewAdam(0.9, 0.999, 1e-8)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	nn, err := neuralnet.New(
+		neuralnet.WithLayers(2, 16, 1),
+		neuralnet.WithActivation(activation.NewReLU()),
+		neuralnet.WithOptimizer(adam),
+		neuralnet.WithSeed(1),
+	)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	// batches of a shuffled set, the last one being smaller
+	runEpoch, err := neuralnet.EpochRunner(nn, 20, []neuralnet.TrainingData{newSamples(t, 30)}, neuralnet.WithBatchSize(8))
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if err := runEpoch(); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	allocations := testing.AllocsPerRun(10, func() {
+		if err := runEpoch(); err != nil {
+			t.Fatalf("expected error to be nil, got %v", err)
+		}
+	})
+	if allocations != 0 {
+		t.Errorf("expected train epoch not to allocate, got %v allocations", allocations)
+	}
+}
+
+func BenchmarkTrainStep(b *testing.B) {
+	nn, err := neuralnet.New(neuralnet.WithLayers(16, 64, 64, 4), neuralnet.WithActivation(activation.NewReLU()), neuralnet.WithSeed(1))
+	if err != nil {
+		b.Fatalf("expected error to be nil, got %v", err)
+	}
+	random := rand.New(rand.NewSource(1))
+	x := make([]float64, 32*16)
+	for i := range x {
+		x[i] = random.Float64()
+	}
+	y := make([]float64, 32*4)
+	for i := range y {
+		y[i] = random.Float64()
+	}
+	X, err := matrix.New(32, 16, x)
+	if err != nil {
+		b.Fatalf("expected error to be nil, got %v", err)
+	}
+	Y, err := matrix.New(32, 4, y)
+	if err != nil {
+		b.Fatalf("expected error to be nil, got %v", err)
+	}
+	data := neuralnet.TrainingData{X: X, Y: Y}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := neuralnet.TrainStep(nn, 0.01, data); err != nil {
+			b.Fatalf("expected error to be nil, got %v", err)
+		}
+	}
 }
@@ -0,0 +1,114 @@
+package neuralnet
+
+import (
+	"fmt"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+	"github.com/buarki/supervised-machine-learning/metric"
+)
+
+// validationConfig tells on which data and how often the
+// network is validated during train.
+type validationConfig struct {
+	data        []TrainingData
+	everyEpochs int
+}
+
+func (c validationConfig) isDue(finishedEpochs int) bool {
+	return len(c.data) > 0 && finishedEpochs%c.everyEpochs == 0
+}
+
+// earlyStoppingConfig tells when train stops for the monitored loss
+// not improving by more than minDelta during patience epochs.
+type earlyStoppingConfig struct {
+	patience int
+	minDelta float64
+}
+
+// Validation is the result of validating the network: the loss, without
+// regularization, and each metric computed over all samples.
+type Validation struct {
+	Loss    float64
+	Metrics map[string]float64
+}
+
+// Validate predicts every given data and measures the loss and the metrics
+// of the predictions. The loss of each batch is weighted by its amount of
+// samples, while metrics are computed once over the samples of all batches.
+func (nn *NeuralNet) Validate(data []TrainingData, metrics ...metric.Metric) (*Validation, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("validation data cannot be empty")
+	}
+	validation := &Validation{}
+	var expected, predicted []float64
+	samples := 0
+	for i, d := range data {
+		if d.X == nil || d.Y == nil {
+			return nil, fmt.Errorf("validation data %d must have X and Y", i)
+		}
+		if d.Y.Columns != data[0].Y.Columns {
+			return nil, fmt.Errorf("validation data %d has %d outputs, expected %d", i, d.Y.Columns, data[0].Y.Columns)
+		}
+		prediction, err := nn.PredictBasedOn(d.X)
+		if err != nil {
+			return nil, fmt.Errorf("failed to predict validation data %d, got %v", i, err)
+		}
+		lossValue, err := nn.loss.Value(d.Y, prediction)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute %s loss of validation data %d, got %v", nn.loss.Name(), i, err)
+		}
+		validation.Loss += lossValue * float64(d.X.Rows)
+		samples += d.X.Rows
+		if len(metrics) > 0 {
+			expected = append(expected, d.Y.FlattenedElements()...)
+			predicted = append(predicted, prediction.FlattenedElements()...)
+		}
+	}
+	validation.Loss /= float64(samples)
+	measured, err := measure(metrics, samples, data[0].Y.Columns, expected, predicted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure validation data, got %v", err)
+	}
+	validation.Metrics = measured
+	return validation, nil
+}
+
+// measure computes each metric once over the samples whose expected and
+// predicted outputs are given row after row.
+func measure(metrics []metric.Metric, samples, outputs int, expected, predicted []float64) (map[string]float64, error) {
+	measured := make(map[string]float64, len(metrics))
+	if len(metrics) == 0 {
+		return measured, nil
+	}
+	expectedMatrix, err := matrix.NewFromSlice(samples, outputs, expected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join expected outputs, got %v", err)
+	}
+	predictedMatrix, err := matrix.NewFromSlice(samples, outputs, predicted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join predictions, got %v", err)
+	}
+	for _, m := range metrics {
+		value, err := m.Value(expectedMatrix, predictedMatrix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute %s, got %v", m.Name(), err)
+		}
+		measured[m.Name()] = value
+	}
+	return measured, nil
+}
+
+// copyParams returns a copy of the value of every param, so
+// they can be restored with adjust after being changed.
+func (nn *NeuralNet) copyParams() ([]*matrix.Matrix, error) {
+	params := nn.Params()
+	copies := make([]*matrix.Matrix, len(params))
+	for i, param := range params {
+		value, err := matrix.New(param.Value.Rows, param.Value.Columns, param.Value.FlattenedElements())
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy %s %d, got %v", param.Name, i, err)
+		}
+		copies[i] = value
+	}
+	return copies, nil
+}
@@ -0,0 +1,221 @@
+package neuralnet_test
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/initializer"
+	"github.com/buarki/supervised-machine-learning/matrix"
+	"github.com/buarki/supervised-machine-learning/metric"
+	"github.com/buarki/supervised-machine-learning/neuralnet"
+	"github.com/buarki/supervised-machine-learning/schedule"
+)
+
+func newConstantNeuralNet(t *testing.T) *neuralnet.NeuralNet {
+	nn, err := neuralnet.New(
+		neuralnet.WithLayers(2, 1),
+		neuralnet.WithLearningRate(0.5),
+		neuralnet.WithInitializer(initializer.NewZeros()),
+		neuralnet.WithSeed(1),
+	)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	return nn
+}
+
+func newData(t *testing.T, x, y []float64) neuralnet.TrainingData {
+	X, err := matrix.New(len(y), 2, x)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	Y, err := matrix.New(len(y), 1, y)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	return neuralnet.TrainingData{X: X, Y: Y}
+}
+
+func TestValidateWeightsBatchesBySamples(t *testing.T) {
+	// with zero params every prediction is sigmoid(0) = 0.5
+	nn := newConstantNeuralNet(t)
+	data := []neuralnet.TrainingData{
+		newData(t, []float64{1, 1, 2, 2, 3, 3}, []float64{1, 1, 1}),
+		newData(t, []float64{4, 4}, []float64{0}),
+	}
+
+	validation, err := nn.Validate(data, metric.NewAccuracy())
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if expected := 0.5 * 0.25; math.Abs(validation.Loss-expected) > 1e-9 {
+		t.Errorf("expected loss to be %v, got %v", expected, validation.Loss)
+	}
+	if expected := 3.0 / 4.0; math.Abs(validation.Metrics["accuracy"]-expected) > 1e-9 {
+		t.Errorf("expected accuracy to be %v, got %v", expected, validation.Metrics["accuracy"])
+	}
+}
+
+func TestValidateComputesMetricsOverAllBatches(t *testing.T) {
+	// predictions are 0.5, so the errors are 0 on the first batch and 2 on the second
+	nn := newConstantNeuralNet(t)
+	data := []neuralnet.TrainingData{
+		newData(t, []float64{1, 1}, []float64{0.5}),
+		newData(t, []float64{2, 2}, []float64{2.5}),
+	}
+
+	validation, err := nn.Validate(data, metric.NewRMSE())
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if expected := math.Sqrt2; math.Abs(validation.Metrics["rmse"]-expected) > 1e-9 {
+		t.Errorf("expected rmse to be %v, got %v", expected, validation.Metrics["rmse"])
+	}
+}
+
+func TestValidateWithoutData(t *testing.T) {
+	if _, err := newConstantNeuralNet(t).Validate(nil); err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+}
+
+// divergingData has training samples the validation ones contradict,
+// so the validation loss is the lowest after the first epoch.
+func divergingData(t *testing.T) (training, validation []neuralnet.TrainingData) {
+	x := []float64{0.5, 0.2, 0.1, 0.9}
+	return []neuralnet.TrainingData{newData(t, x, []float64{1, 1})}, []neuralnet.TrainingData{newData(t, x, []float64{0, 0})}
+}
+
+func TestTrainWithEarlyStopping(t *testing.T) {
+	training, validation := divergingData(t)
+	nn := newConstantNeuralNet(t)
+	s := &recordingSchedule{}
+
+	_, err := neuralnet.Train(nn, 100, training,
+		neuralnet.WithSchedule(s),
+		neuralnet.WithValidation(validation, 1),
+		neuralnet.WithEarlyStopping(3, 0),
+	)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if len(s.calls) != 4 {
+		t.Errorf("expected train to stop after 4 epochs, got %d", len(s.calls))
+	}
+
+	best := newConstantNeuralNet(t)
+	if _, err := neuralnet.Train(best, 1, training, neuralnet.WithSchedule(&recordingSchedule{})); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	for i, w := range best.Weights() {
+		ensureMatricesAreEqual(t, nn.Weights()[i], w)
+	}
+	for i, b := range best.Biases() {
+		ensureMatricesAreEqual(t, nn.Biases()[i], b)
+	}
+}
+
+func TestTrainWithEarlyStoppingAndMinDelta(t *testing.T) {
+	training := []neuralnet.TrainingData{newData(t, []float64{0.5, 0.2, 0.1, 0.9}, []float64{0.6, 0.4})}
+	nn := newConstantNeuralNet(t)
+	s := &recordingSchedule{}
+
+	// the training loss keeps decreasing, but by less than min delta
+	if _, err := neuralnet.Train(nn, 100, training, neuralnet.WithSchedule(s), neuralnet.WithEarlyStopping(2, 1)); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if len(s.calls) != 3 {
+		t.Errorf("expected train to stop after 3 epochs, got %d", len(s.calls))
+	}
+}
+
+func TestTrainWithValidationInterval(t *testing.T) {
+	training, validation := divergingData(t)
+	nn := newConstantNeuralNet(t)
+	s := &recordingSchedule{}
+
+	// validating every 4 epochs, the loss only gets worse from the 4th one
+	_, err := neuralnet.Train(nn, 100, training,
+		neuralnet.WithSchedule(s),
+		neuralnet.WithValidation(validation, 4),
+		neuralnet.WithMetrics(metric.NewRMSE()),
+		neuralnet.WithEarlyStopping(1, 0),
+	)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if len(s.calls) != 8 {
+		t.Errorf("expected train to stop after 8 epochs, got %d", len(s.calls))
+	}
+}
+
+func TestTrainWithInvalidValidation(t *testing.T) {
+	training, validation := divergingData(t)
+	testCases := [][]neuralnet.TrainOption{
+		{neuralnet.WithValidation(validation, 0)},
+		{neuralnet.WithEarlyStopping(0, 0)},
+		{neuralnet.WithEarlyStopping(1, -1)},
+	}
+	for _, options := range testCases {
+		if _, err := neuralnet.Train(newConstantNeuralNet(t), 1, training, options...); err == nil {
+			t.Errorf("expected err to be not nil")
+		}
+	}
+}
+
+func TestResumeKeepsBestParamsOfEarlyStopping(t *testing.T) {
+	training, validation := divergingData(t)
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	options := []neuralnet.TrainOption{
+		neuralnet.WithValidation(validation, 1),
+		neuralnet.WithEarlyStopping(5, 0),
+		neuralnet.WithCheckpoint(path, 1),
+	}
+
+	interrupted := newConstantNeuralNet(t)
+	if _, err := neuralnet.Train(interrupted, 3, training, options...); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	resumed := newConstantNeuralNet(t)
+	if _, err := neuralnet.Resume(resumed, path, 100, training, options...); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	uninterrupted := newConstantNeuralNet(t)
+	if _, err := neuralnet.Train(uninterrupted, 100, training, options...); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	for i, w := range uninterrupted.Weights() {
+		ensureMatricesAreEqual(t, resumed.Weights()[i], w)
+	}
+}
+
+func TestTrainReducesRateOnPlateauOfValidationLoss(t *testing.T) {
+	training, validation := divergingData(t)
+	nn := newConstantNeuralNet(t)
+	s, err := schedule.NewReduceOnPlateau(0.5, 0.5, 1, 0, 0.01)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	history, err := neuralnet.Train(nn, 4, training,
+		neuralnet.WithSchedule(s),
+		neuralnet.WithValidation(validation, 1),
+	)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	epochs := history.Epochs
+	for i := 1; i < len(epochs); i++ {
+		if epochs[i].Loss >= epochs[i-1].Loss {
+			t.Errorf("expected training loss to keep falling, got %v after %v", epochs[i].Loss, epochs[i-1].Loss)
+		}
+		if *epochs[i].ValidationLoss < *epochs[i-1].ValidationLoss {
+			t.Errorf("expected validation loss to stall, got %v after %v", *epochs[i].ValidationLoss, *epochs[i-1].ValidationLoss)
+		}
+	}
+	if rate := epochs[len(epochs)-1].LearningRate; rate >= 0.5 {
+		t.Errorf("expected rate to be reduced on the validation plateau, got %v", rate)
+	}
+}
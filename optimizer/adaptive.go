@@ -0,0 +1,246 @@
+package optimizer
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+// Adagrad scales the learning rate of each element by the
+// history of its squared gradients:
+//
+//	squares = squares + gradient^2
+//	param = param - learningRate*gradient/(sqrt(squares) + epsilon)
+type Adagrad struct {
+	epsilon float64
+	squares slots
+
+	workspace workspace
+}
+
+// NewAdagrad creates an Adagrad optimizer. Epsilon avoids divisions by zero.
+func NewAdagrad(epsilon float64) (*Adagrad, error) {
+	if err := checkPositive("epsilon", epsilon); err != nil {
+		return nil, err
+	}
+	return &Adagrad{epsilon: epsilon}, nil
+}
+
+func (o *Adagrad) Config() Config {
+	return Config{Name: "adagrad", Hyperparameters: map[string]float64{"epsilon": o.epsilon}}
+}
+
+func (o *Adagrad) State() State {
+	return State{Slots: map[string][][]float64{"squares": o.squares.copy()}}
+}
+
+func (o *Adagrad) SetState(state State) error {
+	o.squares = slotsFrom(state, "squares")
+	return nil
+}
+
+func (o *Adagrad) Update(learningRate float64, params, gradients []*matrix.Matrix) ([]*matrix.Matrix, error) {
+	return update(learningRate, params, gradients, o.updateFlat)
+}
+
+func (o *Adagrad) UpdateInPlace(learningRate float64, params, gradients []*matrix.Matrix) error {
+	return o.workspace.updateInPlace(learningRate, params, gradients, o.updateFlat)
+}
+
+func (o *Adagrad) updateFlat(learningRate float64, flatParams, flatGradients [][]float64) error {
+	if err := o.squares.ensure(flatParams); err != nil {
+		return err
+	}
+	for i, param := range flatParams {
+		squares := o.squares[i]
+		for j := range param {
+			gradient := flatGradients[i][j]
+			squares[j] += gradient * gradient
+			param[j] -= learningRate * gradient / (math.Sqrt(squares[j]) + o.epsilon)
+		}
+	}
+	return nil
+}
+
+// RMSProp is like Adagrad but keeps a moving average of the squared
+// gradients, so the learning rate doesn't shrink forever:
+//
+//	squares = decay*squares + (1 - decay)*gradient^2
+//	param = param - learningRate*gradient/(sqrt(squares) + epsilon)
+type RMSProp struct {
+	decay   float64
+	epsilon float64
+	squares slots
+
+	workspace workspace
+}
+
+// NewRMSProp creates a RMSProp optimizer. Decay must be in [0, 1),
+// 0.9 being a common choice.
+func NewRMSProp(decay, epsilon float64) (*RMSProp, error) {
+	if err := checkRange("decay", decay, 0, 1); err != nil {
+		return nil, err
+	}
+	if err := checkPositive("epsilon", epsilon); err != nil {
+		return nil, err
+	}
+	return &RMSProp{decay: decay, epsilon: epsilon}, nil
+}
+
+func (o *RMSProp) Config() Config {
+	return Config{Name: "rmsprop", Hyperparameters: map[string]float64{"decay": o.decay, "epsilon": o.epsilon}}
+}
+
+func (o *RMSProp) State() State {
+	return State{Slots: map[string][][]float64{"squares": o.squares.copy()}}
+}
+
+func (o *RMSProp) SetState(state State) error {
+	o.squares = slotsFrom(state, "squares")
+	return nil
+}
+
+func (o *RMSProp) Update(learningRate float64, params, gradients []*matrix.Matrix) ([]*matrix.Matrix, error) {
+	return update(learningRate, params, gradients, o.updateFlat)
+}
+
+func (o *RMSProp) UpdateInPlace(learningRate float64, params, gradients []*matrix.Matrix) error {
+	return o.workspace.updateInPlace(learningRate, params, gradients, o.updateFlat)
+}
+
+func (o *RMSProp) updateFlat(learningRate float64, flatParams, flatGradients [][]float64) error {
+	if err := o.squares.ensure(flatParams); err != nil {
+		return err
+	}
+	for i, param := range flatParams {
+		squares := o.squares[i]
+		for j := range param {
+			gradient := flatGradients[i][j]
+			squares[j] = o.decay*squares[j] + (1-o.decay)*gradient*gradient
+			param[j] -= learningRate * gradient / (math.Sqrt(squares[j]) + o.epsilon)
+		}
+	}
+	return nil
+}
+
+// Adam keeps moving averages of the gradients (first moment) and of the squared
+// gradients (second moment), corrects their bias towards zero on the first steps
+// and uses them to scale each element step:
+//
+//	m = beta1*m + (1 - beta1)*gradient
+//	v = beta2*v + (1 - beta2)*gradient^2
+//	param = param - learningRate*(m/(1 - beta1^t))/(sqrt(v/(1 - beta2^t)) + epsilon)
+type Adam struct {
+	beta1   float64
+	beta2   float64
+	epsilon float64
+	// weightDecay is only used by AdamW
+	weightDecay float64
+
+	step          int
+	firstMoments  slots
+	secondMoments slots
+
+	workspace workspace
+}
+
+// NewAdam creates an Adam optimizer. Betas must be in [0, 1), common
+// choices being beta1 = 0.9, beta2 = 0.999 and epsilon = 1e-8.
+func NewAdam(beta1, beta2, epsilon float64) (*Adam, error) {
+	if err := checkRange("beta1", beta1, 0, 1); err != nil {
+		return nil, err
+	}
+	if err := checkRange("beta2", beta2, 0, 1); err != nil {
+		return nil, err
+	}
+	if err := checkPositive("epsilon", epsilon); err != nil {
+		return nil, err
+	}
+	return &Adam{beta1: beta1, beta2: beta2, epsilon: epsilon}, nil
+}
+
+func (o *Adam) Config() Config {
+	return Config{Name: "adam", Hyperparameters: map[string]float64{"beta1": o.beta1, "beta2": o.beta2, "epsilon": o.epsilon}}
+}
+
+func (o *Adam) State() State {
+	return State{
+		Step: o.step,
+		Slots: map[string][][]float64{
+			"firstMoments":  o.firstMoments.copy(),
+			"secondMoments": o.secondMoments.copy(),
+		},
+	}
+}
+
+func (o *Adam) SetState(state State) error {
+	if state.Step < 0 {
+		return fmt.Errorf("step must be >= 0, received %d", state.Step)
+	}
+	o.step = state.Step
+	o.firstMoments = slotsFrom(state, "firstMoments")
+	o.secondMoments = slotsFrom(state, "secondMoments")
+	return nil
+}
+
+func (o *Adam) Update(learningRate float64, params, gradients []*matrix.Matrix) ([]*matrix.Matrix, error) {
+	return update(learningRate, params, gradients, o.updateFlat)
+}
+
+func (o *Adam) UpdateInPlace(learningRate float64, params, gradients []*matrix.Matrix) error {
+	return o.workspace.updateInPlace(learningRate, params, gradients, o.updateFlat)
+}
+
+func (o *Adam) updateFlat(learningRate float64, flatParams, flatGradients [][]float64) error {
+	if err := o.firstMoments.ensure(flatParams); err != nil {
+		return err
+	}
+	if err := o.secondMoments.ensure(flatParams); err != nil {
+		return err
+	}
+	o.step++
+	firstMomentCorrection := 1 - math.Pow(o.beta1, float64(o.step))
+	secondMomentCorrection := 1 - math.Pow(o.beta2, float64(o.step))
+	for i, param := range flatParams {
+		m := o.firstMoments[i]
+		v := o.secondMoments[i]
+		for j := range param {
+			gradient := flatGradients[i][j]
+			m[j] = o.beta1*m[j] + (1-o.beta1)*gradient
+			v[j] = o.beta2*v[j] + (1-o.beta2)*gradient*gradient
+			mHat := m[j] / firstMomentCorrection
+			vHat := v[j] / secondMomentCorrection
+			param[j] -= learningRate * (mHat/(math.Sqrt(vHat)+o.epsilon) + o.weightDecay*param[j])
+		}
+	}
+	return nil
+}
+
+// AdamW is Adam with decoupled weight decay: instead of adding a penalty
+// to the gradients, params are shrunk directly on each step by
+// learningRate*weightDecay*param.
+type AdamW struct {
+	Adam
+}
+
+// NewAdamW creates an AdamW optimizer. Weight decay must be >= 0,
+// 0.01 being a common choice.
+func NewAdamW(beta1, beta2, epsilon, weightDecay float64) (*AdamW, error) {
+	adam, err := NewAdam(beta1, beta2, epsilon)
+	if err != nil {
+		return nil, err
+	}
+	if weightDecay < 0 {
+		return nil, fmt.Errorf("weight decay must be >= 0, received %v", weightDecay)
+	}
+	adam.weightDecay = weightDecay
+	return &AdamW{Adam: *adam}, nil
+}
+
+func (o *AdamW) Config() Config {
+	config := o.Adam.Config()
+	config.Name = "adamw"
+	config.Hyperparameters["weightDecay"] = o.weightDecay
+	return config
+}
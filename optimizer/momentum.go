@@ -0,0 +1,115 @@
+package optimizer
+
+import "github.com/buarki/supervised-machine-learning/matrix"
+
+// Momentum implements gradient descent with momentum. It keeps a velocity
+// per param that accumulates past gradients:
+//
+//	velocity = momentum*velocity + gradient
+//	param = param - learningRate*velocity
+type Momentum struct {
+	momentum float64
+	velocity slots
+
+	workspace workspace
+}
+
+// NewMomentum creates a momentum optimizer. Momentum must be in [0, 1),
+// 0.9 being a common choice.
+func NewMomentum(momentum float64) (*Momentum, error) {
+	if err := checkRange("momentum", momentum, 0, 1); err != nil {
+		return nil, err
+	}
+	return &Momentum{momentum: momentum}, nil
+}
+
+func (o *Momentum) Config() Config {
+	return Config{Name: "momentum", Hyperparameters: map[string]float64{"momentum": o.momentum}}
+}
+
+func (o *Momentum) State() State {
+	return State{Slots: map[string][][]float64{"velocity": o.velocity.copy()}}
+}
+
+func (o *Momentum) SetState(state State) error {
+	o.velocity = slotsFrom(state, "velocity")
+	return nil
+}
+
+func (o *Momentum) Update(learningRate float64, params, gradients []*matrix.Matrix) ([]*matrix.Matrix, error) {
+	return update(learningRate, params, gradients, o.updateFlat)
+}
+
+func (o *Momentum) UpdateInPlace(learningRate float64, params, gradients []*matrix.Matrix) error {
+	return o.workspace.updateInPlace(learningRate, params, gradients, o.updateFlat)
+}
+
+func (o *Momentum) updateFlat(learningRate float64, flatParams, flatGradients [][]float64) error {
+	if err := o.velocity.ensure(flatParams); err != nil {
+		return err
+	}
+	for i, param := range flatParams {
+		velocity := o.velocity[i]
+		for j := range param {
+			velocity[j] = o.momentum*velocity[j] + flatGradients[i][j]
+			param[j] -= learningRate * velocity[j]
+		}
+	}
+	return nil
+}
+
+// Nesterov implements the Nesterov accelerated gradient, which looks
+// ahead along the velocity before stepping:
+//
+//	velocity = momentum*velocity + gradient
+//	param = param - learningRate*(gradient + momentum*velocity)
+type Nesterov struct {
+	momentum float64
+	velocity slots
+
+	workspace workspace
+}
+
+// NewNesterov creates a Nesterov optimizer. Momentum must be in [0, 1).
+func NewNesterov(momentum float64) (*Nesterov, error) {
+	if err := checkRange("momentum", momentum, 0, 1); err != nil {
+		return nil, err
+	}
+	return &Nesterov{momentum: momentum}, nil
+}
+
+func (o *Nesterov) Config() Config {
+	return Config{Name: "nesterov", Hyperparameters: map[string]float64{"momentum": o.momentum}}
+}
+
+func (o *Nesterov) State() State {
+	return State{Slots: map[string][][]float64{"velocity": o.velocity.copy()}}
+}
+
+func (o *Nesterov) SetState(state State) error {
+	o.velocity = slotsFrom(state, "velocity")
+	return nil
+}
+
+func (o *Nesterov) Update(learningRate float64, params, gradients []*matrix.Matrix) ([]*matrix.Matrix, error) {
+	return update(learningRate, params, gradients, o.updateFlat)
+}
+
+func (o *Nesterov) UpdateInPlace(learningRate float64, params, gradients []*matrix.Matrix) error {
+	return o.workspace.updateInPlace(learningRate, params, gradients, o.updateFlat)
+}
+
+func (o *Nesterov) updateFlat(learningRate float64, flatParams, flatGradients [][]float64) error {
+	if err := o.velocity.ensure(flatParams); err != nil {
+		return err
+	}
+	for i, param := range flatParams {
+		velocity := o.velocity[i]
+		for j := range param {
+			gradient := flatGradients[i][j]
+			velocity[j] = o.momentum*velocity[j] + gradient
+			param[j] -= learningRate * (gradient + o.momentum*velocity[j])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package optimizer
+
+import (
+	"fmt"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+)
+
+// Optimizer computes the new value of each param given its gradient. Optimizers
+// keeping per-parameter state (velocities, moments and so on) identify each
+// param by its position, so params[i] and gradients[i] must always refer to the
+// same parameter on every call.
+type Optimizer interface {
+	Update(learningRate float64, params, gradients []*matrix.Matrix) ([]*matrix.Matrix, error)
+}
+
+// InPlace is implemented by optimizers able to change the given params
+// instead of returning new ones. After the first call they update params
+// without allocating, as long as params keep the same shapes.
+type InPlace interface {
+	UpdateInPlace(learningRate float64, params, gradients []*matrix.Matrix) error
+}
+
+// State holds what an optimizer has accumulated from past updates, so
+// training can be saved on a checkpoint and resumed later. Slots maps
+// the name of each kind of per-param state, such as "velocity", to
+// its values.
+type State struct {
+	Step  int                    `json:"step,omitempty"`
+	Slots map[string][][]float64 `json:"slots,omitempty"`
+}
+
+// Stateful is implemented by optimizers keeping state between updates.
+type Stateful interface {
+	State() State
+	SetState(state State) error
+}
+
+// Config describes an optimizer by its name and hyperparameters, so it can
+// be written on model files and built again with FromConfig.
+type Config struct {
+	Name            string             `json:"name"`
+	Hyperparameters map[string]float64 `json:"hyperparameters,omitempty"`
+}
+
+// Configurable is implemented by optimizers able to describe themselves.
+type Configurable interface {
+	Config() Config
+}
+
+// FromConfig builds the optimizer described by config, as returned by the
+// Config method of the optimizers of this package. Its state starts empty.
+func FromConfig(config Config) (Optimizer, error) {
+	names, ok := hyperparameterNames[config.Name]
+	if !ok {
+		return nil, fmt.Errorf("unknown optimizer %q", config.Name)
+	}
+	for _, name := range names {
+		if _, ok := config.Hyperparameters[name]; !ok {
+			return nil, fmt.Errorf("optimizer %s must have %s", config.Name, name)
+		}
+	}
+	h := config.Hyperparameters
+	switch config.Name {
+	case "momentum":
+		return NewMomentum(h["momentum"])
+	case "nesterov":
+		return NewNesterov(h["momentum"])
+	case "adagrad":
+		return NewAdagrad(h["epsilon"])
+	case "rmsprop":
+		return NewRMSProp(h["decay"], h["epsilon"])
+	case "adam":
+		return NewAdam(h["beta1"], h["beta2"], h["epsilon"])
+	case "adamw":
+		return NewAdamW(h["beta1"], h["beta2"], h["epsilon"], h["weightDecay"])
+	}
+	return NewSGD(), nil
+}
+
+// hyperparameterNames are the hyperparameters of each optimizer FromConfig builds.
+var hyperparameterNames = map[string][]string{
+	"sgd":      nil,
+	"momentum": {"momentum"},
+	"nesterov": {"momentum"},
+	"adagrad":  {"epsilon"},
+	"rmsprop":  {"decay", "epsilon"},
+	"adam":     {"beta1", "beta2", "epsilon"},
+	"adamw":    {"beta1", "beta2", "epsilon", "weightDecay"},
+}
+
+// slots holds one float64 slice per param, each with as many elements as the
+// param. It is lazily allocated on the first update so optimizers don't need
+// to know the params in advance.
+type slots [][]float64
+
+func (s *slots) ensure(params [][]float64) error {
+	if *s == nil {
+		*s = make([][]float64, len(params))
+		for i, param := range params {
+			(*s)[i] = make([]float64, len(param))
+		}
+		return nil
+	}
+	if len(*s) != len(params) {
+		return fmt.Errorf("optimizer state has %d params, received %d", len(*s), len(params))
+	}
+	for i, param := range params {
+		if len((*s)[i]) != len(param) {
+			return fmt.Errorf("optimizer state of param %d has %d elements, received %d", i, len((*s)[i]), len(param))
+		}
+	}
+	return nil
+}
+
+// copy returns a deep copy of the slots values.
+func (s slots) copy() [][]float64 {
+	if s == nil {
+		return nil
+	}
+	values := make([][]float64, len(s))
+	for i := range s {
+		values[i] = append([]float64{}, s[i]...)
+	}
+	return values
+}
+
+// slotsFrom returns a deep copy of the slot with the given name.
+func slotsFrom(state State, name string) slots {
+	return slots(slots(state.Slots[name]).copy())
+}
+
+// flatUpdate updates flat params given their flat gradients,
+// which is what each optimizer implements.
+type flatUpdate func(learningRate float64, params, gradients [][]float64) error
+
+// update runs updateFlat on flat copies of params and returns
+// them arranged as matrices, leaving params unchanged.
+func update(learningRate float64, params, gradients []*matrix.Matrix, updateFlat flatUpdate) ([]*matrix.Matrix, error) {
+	flatParams, flatGradients, err := flatten(params, gradients)
+	if err != nil {
+		return nil, err
+	}
+	if err := updateFlat(learningRate, flatParams, flatGradients); err != nil {
+		return nil, err
+	}
+	return rebuild(params, flatParams)
+}
+
+// check tells whether params and gradients are compatible.
+func check(params, gradients []*matrix.Matrix) error {
+	if len(params) != len(gradients) {
+		return fmt.Errorf("expected one gradient per param, received %d params and %d gradients", len(params), len(gradients))
+	}
+	for i := range params {
+		if params[i] == nil || gradients[i] == nil {
+			return fmt.Errorf("param %d and its gradient cannot be nil", i)
+		}
+		if params[i].Rows != gradients[i].Rows || params[i].Columns != gradients[i].Columns {
+			return fmt.Errorf("gradient of param %d has different shape, expected (%dx%d), received (%dx%d)", i, params[i].Rows, params[i].Columns, gradients[i].Rows, gradients[i].Columns)
+		}
+	}
+	return nil
+}
+
+// flatten checks params and gradients are compatible and returns
+// their elements so optimizers can work on plain slices.
+func flatten(params, gradients []*matrix.Matrix) ([][]float64, [][]float64, error) {
+	if err := check(params, gradients); err != nil {
+		return nil, nil, err
+	}
+	flatParams := make([][]float64, len(params))
+	flatGradients := make([][]float64, len(gradients))
+	for i := range params {
+		// params are updated on their flat copy
+		flatParams[i] = params[i].Copy().FlattenedElements()
+		flatGradients[i] = gradients[i].FlattenedElements()
+	}
+	return flatParams, flatGradients, nil
+}
+
+// workspace keeps the flat params and gradients an optimizer works on
+// when updating in place, so updates after the first one don't allocate.
+type workspace struct {
+	params    [][]float64
+	gradients [][]float64
+}
+
+// updateInPlace runs updateFlat on the elements of params. Contiguous
+// params are updated directly on their storage, while views of some
+// columns of another matrix are updated on a copy written back after.
+func (w *workspace) updateInPlace(learningRate float64, params, gradients []*matrix.Matrix, updateFlat flatUpdate) error {
+	if err := check(params, gradients); err != nil {
+		return err
+	}
+	if len(w.params) != len(params) {
+		w.params = make([][]float64, len(params))
+		w.gradients = make([][]float64, len(gradients))
+	}
+	for i, param := range params {
+		w.params[i] = param.FlattenedElements()
+		w.gradients[i] = gradients[i].FlattenedElements()
+	}
+	if err := updateFlat(learningRate, w.params, w.gradients); err != nil {
+		return err
+	}
+	for i, param := range params {
+		if param.Contiguous() {
+			continue
+		}
+		if err := param.SetFlattenedElements(w.params[i]); err != nil {
+			return fmt.Errorf("failed to update param %d, got %v", i, err)
+		}
+	}
+	return nil
+}
+
+// rebuild arranges the updated flat params back on matrices shaped as the original ones.
+func rebuild(params []*matrix.Matrix, flatParams [][]float64) ([]*matrix.Matrix, error) {
+	newParams := make([]*matrix.Matrix, len(params))
+	for i, param := range params {
+		newParam, err := matrix.NewFromSlice(param.Rows, param.Columns, flatParams[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to rebuild param %d, got %v", i, err)
+		}
+		newParams[i] = newParam
+	}
+	return newParams, nil
+}
+
+func checkRange(name string, value, lowerBound, upperBound float64) error {
+	if value < lowerBound || value >= upperBound {
+		return fmt.Errorf("%s must be in [%v, %v), received %v", name, lowerBound, upperBound, value)
+	}
+	return nil
+}
+
+func checkPositive(name string, value float64) error {
+	if value <= 0 {
+		return fmt.Errorf("%s must be > 0, received %v", name, value)
+	}
+	return nil
+}
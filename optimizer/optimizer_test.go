@@ -0,0 +1,368 @@
+package optimizer_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/matrix"
+	"github.com/buarki/supervised-machine-learning/optimizer"
+)
+
+// minimize runs the optimizer over f(p) = 0.5*||p - target||^2,
+// whose gradient is p - target, and returns the final p.
+func minimize(t *testing.T, o optimizer.Optimizer, learningRate float64, steps int) []float64 {
+	target := []float64{3, -2, 0.5, 1}
+	p, err := matrix.New(2, 2, []float64{0, 0, 0, 0})
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	for step := 0; step < steps; step++ {
+		values := p.FlattenedElements()
+		gradientValues := make([]float64, len(values))
+		for i := range values {
+			gradientValues[i] = values[i] - target[i]
+		}
+		gradient, err := matrix.New(2, 2, gradientValues)
+		if err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		newParams, err := o.Update(learningRate, []*matrix.Matrix{p}, []*matrix.Matrix{gradient})
+		if err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		p = newParams[0]
+	}
+	values := p.FlattenedElements()
+	for i := range values {
+		values[i] -= target[i]
+	}
+	return values
+}
+
+func TestOptimizersConverge(t *testing.T) {
+	momentum, err := optimizer.NewMomentum(0.9)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	nesterov, err := optimizer.NewNesterov(0.9)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	rmsProp, err := optimizer.NewRMSProp(0.9, 1e-8)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	adagrad, err := optimizer.NewAdagrad(1e-8)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	adam, err := optimizer.NewAdam(0.9, 0.999, 1e-8)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	adamW, err := optimizer.NewAdamW(0.9, 0.999, 1e-8, 0)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	testCases := []struct {
+		name         string
+		optimizer    optimizer.Optimizer
+		learningRate float64
+	}{
+		{name: "sgd", optimizer: optimizer.NewSGD(), learningRate: 0.1},
+		{name: "momentum", optimizer: momentum, learningRate: 0.05},
+		{name: "nesterov", optimizer: nesterov, learningRate: 0.05},
+		{name: "rmsprop", optimizer: rmsProp, learningRate: 0.01},
+		{name: "adagrad", optimizer: adagrad, learningRate: 0.5},
+		{name: "adam", optimizer: adam, learningRate: 0.05},
+		{name: "adamw", optimizer: adamW, learningRate: 0.05},
+	}
+	for _, testCase := range testCases {
+		distances := minimize(t, testCase.optimizer, testCase.learningRate, 1000)
+		for i, distance := range distances {
+			if math.Abs(distance) > 1e-2 {
+				t.Errorf("%s: expected element %d to converge, got distance %v", testCase.name, i, distance)
+			}
+		}
+	}
+}
+
+func TestAdamFirstStepHasLearningRateSize(t *testing.T) {
+	adam, err := optimizer.NewAdam(0.9, 0.999, 1e-8)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	p, _ := matrix.New(1, 2, []float64{1, 1})
+	gradient, _ := matrix.New(1, 2, []float64{100, -0.001})
+
+	newParams, err := adam.Update(0.1, []*matrix.Matrix{p}, []*matrix.Matrix{gradient})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	// bias correction makes the first step be learningRate*sign(gradient)
+	expected := []float64{0.9, 1.1}
+	for i, value := range newParams[0].FlattenedElements() {
+		if math.Abs(value-expected[i]) > 1e-6 {
+			t.Errorf("expected element %d to be %v, got %v", i, expected[i], value)
+		}
+	}
+}
+
+func TestAdamWDecaysParams(t *testing.T) {
+	adamW, err := optimizer.NewAdamW(0.9, 0.999, 1e-8, 0.5)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	p, _ := matrix.New(1, 1, []float64{2})
+	gradient, _ := matrix.New(1, 1, []float64{0})
+
+	newParams, err := adamW.Update(0.1, []*matrix.Matrix{p}, []*matrix.Matrix{gradient})
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	value, _ := newParams[0].GetAt(0, 0)
+	if expected := 2 - 0.1*0.5*2; math.Abs(value-expected) > 1e-9 {
+		t.Errorf("expected param to be %v, got %v", expected, value)
+	}
+}
+
+func TestUpdateWithDifferentParamsAfterFirstStep(t *testing.T) {
+	momentum, err := optimizer.NewMomentum(0.9)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	p, _ := matrix.New(1, 2, []float64{1, 1})
+	gradient, _ := matrix.New(1, 2, []float64{1, 1})
+	if _, err := momentum.Update(0.1, []*matrix.Matrix{p}, []*matrix.Matrix{gradient}); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+
+	_, err = momentum.Update(0.1, []*matrix.Matrix{p, p}, []*matrix.Matrix{gradient, gradient})
+	if err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+}
+
+func TestUpdateWithGradientOfDifferentShape(t *testing.T) {
+	p, _ := matrix.New(1, 2, []float64{1, 1})
+	gradient, _ := matrix.New(2, 1, []float64{1, 1})
+
+	_, err := optimizer.NewSGD().Update(0.1, []*matrix.Matrix{p}, []*matrix.Matrix{gradient})
+	if err == nil {
+		t.Errorf("expected err to be not nil")
+	}
+}
+
+func TestInvalidHyperparameters(t *testing.T) {
+	if _, err := optimizer.NewMomentum(1); err == nil {
+		t.Errorf("expected momentum of 1 to be rejected")
+	}
+	if _, err := optimizer.NewAdam(0.9, 0.999, 0); err == nil {
+		t.Errorf("expected epsilon of 0 to be rejected")
+	}
+	if _, err := optimizer.NewAdamW(0.9, 0.999, 1e-8, -1); err == nil {
+		t.Errorf("expected negative weight decay to be rejected")
+	}
+}
+
+func TestSetStateRestoresOptimizers(t *testing.T) {
+	newOptimizers := []func() (optimizer.Optimizer, error){
+		func() (optimizer.Optimizer, error) { return optimizer.NewMomentum(0.9) },
+		func() (optimizer.Optimizer, error) { return optimizer.NewNesterov(0.9) },
+		func() (optimizer.Optimizer, error) { return optimizer.NewAdagrad(1e-8) },
+		func() (optimizer.Optimizer, error) { return optimizer.NewRMSProp(0.9, 1e-8) },
+		func() (optimizer.Optimizer, error) { return optimizer.NewAdam(0.9, 0.999, 1e-8) },
+		func() (optimizer.Optimizer, error) { return optimizer.NewAdamW(0.9, 0.999, 1e-8, 0.01) },
+	}
+	p, err := matrix.New(1, 3, []float64{0.5, -1, 2})
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	gradient, err := matrix.New(1, 3, []float64{0.1, -0.3, 0.2})
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	for _, newOptimizer := range newOptimizers {
+		original, err := newOptimizer()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		restored, err := newOptimizer()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		for step := 0; step < 3; step++ {
+			if _, err := original.Update(0.1, []*matrix.Matrix{p}, []*matrix.Matrix{gradient}); err != nil {
+				t.Fatalf("expected err to be nil, got %v", err)
+			}
+		}
+		if err := restored.(optimizer.Stateful).SetState(original.(optimizer.Stateful).State()); err != nil {
+			t.Errorf("%T: expected err to be nil, got %v", original, err)
+		}
+
+		expected, err := original.Update(0.1, []*matrix.Matrix{p}, []*matrix.Matrix{gradient})
+		if err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		received, err := restored.Update(0.1, []*matrix.Matrix{p}, []*matrix.Matrix{gradient})
+		if err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		expectedValues, receivedValues := expected[0].FlattenedElements(), received[0].FlattenedElements()
+		for i := range expectedValues {
+			if expectedValues[i] != receivedValues[i] {
+				t.Errorf("%T: expected restored optimizer to give %v, got %v", original, expectedValues, receivedValues)
+				break
+			}
+		}
+	}
+}
+
+func TestFromConfigRebuildsOptimizers(t *testing.T) {
+	newOptimizers := []func() (optimizer.Optimizer, error){
+		func() (optimizer.Optimizer, error) { return optimizer.NewSGD(), nil },
+		func() (optimizer.Optimizer, error) { return optimizer.NewMomentum(0.8) },
+		func() (optimizer.Optimizer, error) { return optimizer.NewNesterov(0.7) },
+		func() (optimizer.Optimizer, error) { return optimizer.NewAdagrad(1e-6) },
+		func() (optimizer.Optimizer, error) { return optimizer.NewRMSProp(0.95, 1e-7) },
+		func() (optimizer.Optimizer, error) { return optimizer.NewAdam(0.85, 0.99, 1e-7) },
+		func() (optimizer.Optimizer, error) { return optimizer.NewAdamW(0.85, 0.99, 1e-7, 0.02) },
+	}
+	for _, newOptimizer := range newOptimizers {
+		original, err := newOptimizer()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		config := original.(optimizer.Configurable).Config()
+
+		rebuilt, err := optimizer.FromConfig(config)
+		if err != nil {
+			t.Fatalf("%s: expected err to be nil, got %v", config.Name, err)
+		}
+		if !reflect.DeepEqual(rebuilt, original) {
+			t.Errorf("%s: expected rebuilt optimizer to be %+v, got %+v", config.Name, original, rebuilt)
+		}
+	}
+}
+
+func TestFromConfigWithInvalidConfig(t *testing.T) {
+	configs := []optimizer.Config{
+		{Name: "lbfgs"},
+		{Name: "adam", Hyperparameters: map[string]float64{"beta1": 0.9, "beta2": 0.999}},
+		{Name: "momentum", Hyperparameters: map[string]float64{"momentum": 1}},
+	}
+	for _, config := range configs {
+		if _, err := optimizer.FromConfig(config); err == nil {
+			t.Errorf("expected config %+v to be rejected", config)
+		}
+	}
+}
+
+func TestUpdateKeepsGivenParams(t *testing.T) {
+	momentum, err := optimizer.NewMomentum(0.9)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	adam, err := optimizer.NewAdam(0.9, 0.999, 1e-8)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	for _, o := range []optimizer.Optimizer{optimizer.NewSGD(), momentum, adam} {
+		p, _ := matrix.New(1, 2, []float64{1, 1})
+		gradient, _ := matrix.New(1, 2, []float64{1, 1})
+
+		if _, err := o.Update(0.1, []*matrix.Matrix{p}, []*matrix.Matrix{gradient}); err != nil {
+			t.Errorf("expected err to be nil, got %v", err)
+		}
+
+		for i, value := range p.FlattenedElements() {
+			if value != 1 {
+				t.Errorf("%T: expected given param element %d to stay 1, got %v", o, i, value)
+			}
+		}
+	}
+}
+
+func TestUpdateInPlaceMatchesUpdate(t *testing.T) {
+	newOptimizers := []func() (optimizer.Optimizer, error){
+		func() (optimizer.Optimizer, error) { return optimizer.NewSGD(), nil },
+		func() (optimizer.Optimizer, error) { return optimizer.NewMomentum(0.9) },
+		func() (optimizer.Optimizer, error) { return optimizer.NewNesterov(0.9) },
+		func() (optimizer.Optimizer, error) { return optimizer.NewAdagrad(1e-8) },
+		func() (optimizer.Optimizer, error) { return optimizer.NewRMSProp(0.9, 1e-8) },
+		func() (optimizer.Optimizer, error) { return optimizer.NewAdam(0.9, 0.999, 1e-8) },
+		func() (optimizer.Optimizer, error) { return optimizer.NewAdamW(0.9, 0.999, 1e-8, 0.01) },
+	}
+	firstGradient, _ := matrix.New(2, 2, []float64{0.1, -0.3, 0.2, 0.4})
+	secondGradient, _ := matrix.New(1, 2, []float64{-0.5, 0.25})
+	gradients := []*matrix.Matrix{firstGradient, secondGradient}
+	for _, newOptimizer := range newOptimizers {
+		updated, err := newOptimizer()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		updatedInPlace, err := newOptimizer()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		first, _ := matrix.New(2, 2, []float64{0.5, -1, 2, 0})
+		second, _ := matrix.New(1, 2, []float64{1, 3})
+		params := []*matrix.Matrix{first, second}
+		// views are updated in place as well
+		wide, _ := matrix.New(2, 4, []float64{9, 0.5, -1, 9, 9, 2, 0, 9})
+		firstView, _ := wide.Slice(0, 2, 1, 3)
+		paramsInPlace := []*matrix.Matrix{firstView, second.Copy()}
+
+		for step := 0; step < 3; step++ {
+			params, err = updated.Update(0.1, params, gradients)
+			if err != nil {
+				t.Fatalf("expected err to be nil, got %v", err)
+			}
+			if err := updatedInPlace.(optimizer.InPlace).UpdateInPlace(0.1, paramsInPlace, gradients); err != nil {
+				t.Fatalf("expected err to be nil, got %v", err)
+			}
+		}
+
+		for i := range params {
+			expected, received := params[i].FlattenedElements(), paramsInPlace[i].FlattenedElements()
+			for j := range expected {
+				if expected[j] != received[j] {
+					t.Errorf("%T: expected param %d updated in place to be %v, got %v", updated, i, expected, received)
+					break
+				}
+			}
+		}
+		if corners := []float64{wide.FlattenedElements()[0], wide.FlattenedElements()[3]}; corners[0] != 9 || corners[1] != 9 {
+			t.Errorf("%T: expected elements out of the view to stay 9, got %v", updated, corners)
+		}
+	}
+}
+
+func TestUpdateInPlaceDoesNotAllocateAfterFirstUpdate(t *testing.T) {
+	adam, err := optimizer.NewAdam(0.9, 0.999, 1e-8)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	params := []*matrix.Matrix{}
+	gradients := []*matrix.Matrix{}
+	for _, size := range []int{6, 3} {
+		param, _ := matrix.New(1, size, make([]float64, size))
+		gradient, _ := matrix.New(1, size, make([]float64, size))
+		params, gradients = append(params, param), append(gradients, gradient)
+	}
+	if err := adam.UpdateInPlace(0.1, params, gradients); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	allocations := testing.AllocsPerRun(10, func() {
+		if err := adam.UpdateInPlace(0.1, params, gradients); err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+	})
+	if allocations != 0 {
+		t.Errorf("expected no allocations, got %v", allocations)
+	}
+}
@@ -0,0 +1,33 @@
+package optimizer
+
+import "github.com/buarki/supervised-machine-learning/matrix"
+
+// SGD implements the plain gradient descent: param = param - learningRate*gradient.
+type SGD struct {
+	workspace workspace
+}
+
+func NewSGD() *SGD {
+	return &SGD{}
+}
+
+func (o *SGD) Config() Config {
+	return Config{Name: "sgd"}
+}
+
+func (o *SGD) Update(learningRate float64, params, gradients []*matrix.Matrix) ([]*matrix.Matrix, error) {
+	return update(learningRate, params, gradients, o.updateFlat)
+}
+
+func (o *SGD) UpdateInPlace(learningRate float64, params, gradients []*matrix.Matrix) error {
+	return o.workspace.updateInPlace(learningRate, params, gradients, o.updateFlat)
+}
+
+func (o *SGD) updateFlat(learningRate float64, flatParams, flatGradients [][]float64) error {
+	for i, param := range flatParams {
+		for j := range param {
+			param[j] -= learningRate * flatGradients[i][j]
+		}
+	}
+	return nil
+}
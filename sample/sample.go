@@ -45,18 +45,14 @@ type BiasSample struct {
 }
 
 func GetBiases() (*BiasSample, error) {
-	knownB2, err := matrix.New(3, 3, []float64{
+	knownB2, err := matrix.New(1, 3, []float64{
 		0.8253202608929586, 0.7053534282602804, -0.8408781071577406,
-		-0.927252738366855435, -0.6540088162020229, 0.97235650593976225,
-		-0.92028292938366855435, -1.202022939292289, -0.3339999997762235,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("expected error to be nil, got %v", err)
 	}
-	knownB3, err := matrix.New(3, 1, []float64{
+	knownB3, err := matrix.New(1, 1, []float64{
 		-0.0729742481518,
-		0.971939328807,
-		-1.200800976263,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("expected error to be nil, got %v", err)
@@ -0,0 +1,298 @@
+package schedule
+
+import (
+	"fmt"
+	"math"
+)
+
+// Schedule gives the learning rate to be used on each training step. Epoch
+// and step are both zero based, where step counts the batches seen since
+// the beginning of the training.
+type Schedule interface {
+	LearningRate(epoch, step int) float64
+}
+
+// Observer is implemented by schedules that adapt the learning rate to a
+// monitored loss. The training loop calls Observe at the end of every epoch.
+type Observer interface {
+	Observe(loss float64)
+}
+
+// Stateful is implemented by schedules whose rate depends on what they
+// observed, so their state can be saved on checkpoints and restored.
+type Stateful interface {
+	State() map[string]float64
+	SetState(state map[string]float64) error
+}
+
+// Constant always gives the same learning rate.
+type Constant struct {
+	rate float64
+}
+
+func NewConstant(rate float64) (*Constant, error) {
+	if err := checkPositive("rate", rate); err != nil {
+		return nil, err
+	}
+	return &Constant{rate: rate}, nil
+}
+
+func (s *Constant) LearningRate(epoch, step int) float64 {
+	return s.rate
+}
+
+// StepDecay multiplies the initial rate by factor every epochsPerDrop
+// epochs: rate = initialRate * factor^floor(epoch/epochsPerDrop).
+type StepDecay struct {
+	initialRate   float64
+	factor        float64
+	epochsPerDrop int
+}
+
+func NewStepDecay(initialRate, factor float64, epochsPerDrop int) (*StepDecay, error) {
+	if err := checkPositive("initial rate", initialRate); err != nil {
+		return nil, err
+	}
+	if err := checkFactor(factor); err != nil {
+		return nil, err
+	}
+	if epochsPerDrop <= 0 {
+		return nil, fmt.Errorf("epochs per drop must be > 0, received %d", epochsPerDrop)
+	}
+	return &StepDecay{initialRate: initialRate, factor: factor, epochsPerDrop: epochsPerDrop}, nil
+}
+
+func (s *StepDecay) LearningRate(epoch, step int) float64 {
+	return s.initialRate * math.Pow(s.factor, float64(epoch/s.epochsPerDrop))
+}
+
+// ExponentialDecay shrinks the rate smoothly on every
+// epoch: rate = initialRate * decayRate^epoch.
+type ExponentialDecay struct {
+	initialRate float64
+	decayRate   float64
+}
+
+func NewExponentialDecay(initialRate, decayRate float64) (*ExponentialDecay, error) {
+	if err := checkPositive("initial rate", initialRate); err != nil {
+		return nil, err
+	}
+	if err := checkFactor(decayRate); err != nil {
+		return nil, err
+	}
+	return &ExponentialDecay{initialRate: initialRate, decayRate: decayRate}, nil
+}
+
+func (s *ExponentialDecay) LearningRate(epoch, step int) float64 {
+	return s.initialRate * math.Pow(s.decayRate, float64(epoch))
+}
+
+// LinearWarmup grows the rate linearly from almost zero to the one given by
+// the wrapped schedule during the first warmupSteps steps, and then gives
+// the wrapped schedule rate as is.
+type LinearWarmup struct {
+	warmupSteps int
+	schedule    Schedule
+}
+
+func NewLinearWarmup(warmupSteps int, schedule Schedule) (*LinearWarmup, error) {
+	if warmupSteps <= 0 {
+		return nil, fmt.Errorf("warmup steps must be > 0, received %d", warmupSteps)
+	}
+	if schedule == nil {
+		return nil, fmt.Errorf("schedule to warm up cannot be nil")
+	}
+	return &LinearWarmup{warmupSteps: warmupSteps, schedule: schedule}, nil
+}
+
+func (s *LinearWarmup) LearningRate(epoch, step int) float64 {
+	rate := s.schedule.LearningRate(epoch, step)
+	if step < s.warmupSteps {
+		return rate * float64(step+1) / float64(s.warmupSteps)
+	}
+	return rate
+}
+
+// Observe forwards the loss to the wrapped schedule if it observes it.
+func (s *LinearWarmup) Observe(loss float64) {
+	if observer, ok := s.schedule.(Observer); ok {
+		observer.Observe(loss)
+	}
+}
+
+// State returns the state of the wrapped schedule, if it has any.
+func (s *LinearWarmup) State() map[string]float64 {
+	if stateful, ok := s.schedule.(Stateful); ok {
+		return stateful.State()
+	}
+	return nil
+}
+
+func (s *LinearWarmup) SetState(state map[string]float64) error {
+	if stateful, ok := s.schedule.(Stateful); ok {
+		return stateful.SetState(state)
+	}
+	return nil
+}
+
+// CosineAnnealing decreases the rate from maxRate to minRate following half
+// a cosine wave over a period of epochs, then restarts from maxRate. Each new
+// period lasts periodMultiplier times the previous one, so 1 keeps all periods
+// of the same length.
+type CosineAnnealing struct {
+	maxRate          float64
+	minRate          float64
+	period           int
+	periodMultiplier int
+}
+
+func NewCosineAnnealing(maxRate, minRate float64, period, periodMultiplier int) (*CosineAnnealing, error) {
+	if err := checkPositive("max rate", maxRate); err != nil {
+		return nil, err
+	}
+	if minRate < 0 || minRate > maxRate {
+		return nil, fmt.Errorf("min rate must be in [0, %v], received %v", maxRate, minRate)
+	}
+	if period <= 0 {
+		return nil, fmt.Errorf("period must be > 0, received %d", period)
+	}
+	if periodMultiplier <= 0 {
+		return nil, fmt.Errorf("period multiplier must be > 0, received %d", periodMultiplier)
+	}
+	return &CosineAnnealing{maxRate: maxRate, minRate: minRate, period: period, periodMultiplier: periodMultiplier}, nil
+}
+
+func (s *CosineAnnealing) LearningRate(epoch, step int) float64 {
+	epochInPeriod, period := s.position(epoch)
+	progress := float64(epochInPeriod) / float64(period)
+	return s.minRate + 0.5*(s.maxRate-s.minRate)*(1+math.Cos(math.Pi*progress))
+}
+
+// position returns how far epoch is from the beginning
+// of its period and the length of that period.
+func (s *CosineAnnealing) position(epoch int) (int, int) {
+	if s.periodMultiplier == 1 {
+		return epoch % s.period, s.period
+	}
+	// period k begins at period*(multiplier^k - 1)/(multiplier - 1), so k
+	// comes from a log, which is then fixed for floating point rounding
+	multiplier := float64(s.periodMultiplier)
+	k := int(math.Log(float64(epoch)*(multiplier-1)/float64(s.period)+1) / math.Log(multiplier))
+	start, period := s.periodStart(k)
+	for k > 0 && start > epoch {
+		k--
+		start, period = s.periodStart(k)
+	}
+	for epoch-start >= period {
+		start += period
+		period *= s.periodMultiplier
+	}
+	return epoch - start, period
+}
+
+// periodStart returns the epoch where period k begins and its length.
+func (s *CosineAnnealing) periodStart(k int) (int, int) {
+	start, period := 0, s.period
+	for i := 0; i < k; i++ {
+		start += period
+		period *= s.periodMultiplier
+	}
+	return start, period
+}
+
+// ReduceOnPlateau multiplies the rate by factor whenever the observed loss
+// doesn't improve by more than minDelta for patience epochs in a row. The
+// rate never goes below minRate.
+type ReduceOnPlateau struct {
+	rate     float64
+	factor   float64
+	patience int
+	minDelta float64
+	minRate  float64
+
+	best              float64
+	epochsWithoutGain int
+}
+
+func NewReduceOnPlateau(initialRate, factor float64, patience int, minDelta, minRate float64) (*ReduceOnPlateau, error) {
+	if err := checkPositive("initial rate", initialRate); err != nil {
+		return nil, err
+	}
+	if err := checkFactor(factor); err != nil {
+		return nil, err
+	}
+	if patience < 0 {
+		return nil, fmt.Errorf("patience must be >= 0, received %d", patience)
+	}
+	if minDelta < 0 {
+		return nil, fmt.Errorf("min delta must be >= 0, received %v", minDelta)
+	}
+	if minRate < 0 || minRate > initialRate {
+		return nil, fmt.Errorf("min rate must be in [0, %v], received %v", initialRate, minRate)
+	}
+	return &ReduceOnPlateau{
+		rate:     initialRate,
+		factor:   factor,
+		patience: patience,
+		minDelta: minDelta,
+		minRate:  minRate,
+		best:     math.Inf(1),
+	}, nil
+}
+
+func (s *ReduceOnPlateau) LearningRate(epoch, step int) float64 {
+	return s.rate
+}
+
+func (s *ReduceOnPlateau) Observe(loss float64) {
+	if loss < s.best-s.minDelta {
+		s.best = loss
+		s.epochsWithoutGain = 0
+		return
+	}
+	s.epochsWithoutGain++
+	if s.epochsWithoutGain >= s.patience {
+		s.rate = math.Max(s.rate*s.factor, s.minRate)
+		s.epochsWithoutGain = 0
+	}
+}
+
+func (s *ReduceOnPlateau) State() map[string]float64 {
+	state := map[string]float64{
+		"rate":              s.rate,
+		"epochsWithoutGain": float64(s.epochsWithoutGain),
+	}
+	// best is infinite until the first loss is observed, which JSON can't hold
+	if !math.IsInf(s.best, 1) {
+		state["best"] = s.best
+	}
+	return state
+}
+
+func (s *ReduceOnPlateau) SetState(state map[string]float64) error {
+	rate, ok := state["rate"]
+	if !ok {
+		return fmt.Errorf("state has no rate")
+	}
+	s.rate = rate
+	s.epochsWithoutGain = int(state["epochsWithoutGain"])
+	s.best = math.Inf(1)
+	if best, ok := state["best"]; ok {
+		s.best = best
+	}
+	return nil
+}
+
+func checkPositive(name string, value float64) error {
+	if value <= 0 {
+		return fmt.Errorf("%s must be > 0, received %v", name, value)
+	}
+	return nil
+}
+
+func checkFactor(factor float64) error {
+	if factor <= 0 || factor > 1 {
+		return fmt.Errorf("factor must be in (0, 1], received %v", factor)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package schedule_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/buarki/supervised-machine-learning/schedule"
+)
+
+const (
+	acceptedError = 1e-12
+)
+
+type expectedRate struct {
+	epoch    int
+	step     int
+	expected float64
+}
+
+func ensureRates(t *testing.T, name string, s schedule.Schedule, expectedRates []expectedRate) {
+	for _, e := range expectedRates {
+		rate := s.LearningRate(e.epoch, e.step)
+		if math.Abs(rate-e.expected) > acceptedError {
+			t.Errorf("%s: expected rate at epoch %d step %d to be %v, got %v", name, e.epoch, e.step, e.expected, rate)
+		}
+	}
+}
+
+func TestConstant(t *testing.T) {
+	s, err := schedule.NewConstant(0.1)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	ensureRates(t, "constant", s, []expectedRate{{0, 0, 0.1}, {100, 1000, 0.1}})
+}
+
+func TestStepDecay(t *testing.T) {
+	s, err := schedule.NewStepDecay(0.1, 0.5, 10)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	ensureRates(t, "step decay", s, []expectedRate{{0, 0, 0.1}, {9, 0, 0.1}, {10, 0, 0.05}, {25, 0, 0.025}})
+}
+
+func TestExponentialDecay(t *testing.T) {
+	s, err := schedule.NewExponentialDecay(0.1, 0.9)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	ensureRates(t, "exponential decay", s, []expectedRate{{0, 0, 0.1}, {1, 0, 0.09}, {2, 0, 0.081}})
+}
+
+func TestLinearWarmup(t *testing.T) {
+	constant, err := schedule.NewConstant(0.1)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	s, err := schedule.NewLinearWarmup(4, constant)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	ensureRates(t, "linear warmup", s, []expectedRate{{0, 0, 0.025}, {0, 1, 0.05}, {1, 3, 0.1}, {5, 50, 0.1}})
+}
+
+func TestCosineAnnealingWithRestarts(t *testing.T) {
+	s, err := schedule.NewCosineAnnealing(0.1, 0.0, 4, 2)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	ensureRates(t, "cosine annealing", s, []expectedRate{
+		{0, 0, 0.1},
+		{2, 0, 0.05},
+		// first restart, the period now lasts 8 epochs
+		{4, 0, 0.1},
+		{8, 0, 0.05},
+		// second restart
+		{12, 0, 0.1},
+	})
+}
+
+func TestCosineAnnealingOnLongRuns(t *testing.T) {
+	for _, periodMultiplier := range []int{1, 2, 3} {
+		s, err := schedule.NewCosineAnnealing(0.1, 0.01, 7, periodMultiplier)
+		if err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		// walking the periods one by one as a reference
+		start, period := 0, 7
+		for epoch := 0; epoch < 200000; epoch++ {
+			if epoch-start >= period {
+				start += period
+				period *= periodMultiplier
+			}
+			progress := float64(epoch-start) / float64(period)
+			expected := 0.01 + 0.5*(0.1-0.01)*(1+math.Cos(math.Pi*progress))
+			if rate := s.LearningRate(epoch, 0); math.Abs(rate-expected) > acceptedError {
+				t.Fatalf("multiplier %d: expected rate of epoch %d to be %v, got %v", periodMultiplier, epoch, expected, rate)
+			}
+		}
+	}
+}
+
+func TestReduceOnPlateau(t *testing.T) {
+	s, err := schedule.NewReduceOnPlateau(0.1, 0.5, 2, 0.01, 0.03)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	losses := []float64{1, 0.5, 0.495, 0.499, 0.3, 0.31, 0.32, 0.33, 0.34, 0.35, 0.36}
+	// the rate halves after 2 epochs without improvement, but never goes below 0.03
+	expectedRates := []float64{0.1, 0.1, 0.1, 0.05, 0.05, 0.05, 0.03, 0.03, 0.03, 0.03, 0.03}
+	for i, loss := range losses {
+		s.Observe(loss)
+		if rate := s.LearningRate(i, i); math.Abs(rate-expectedRates[i]) > acceptedError {
+			t.Errorf("expected rate after observing loss %d to be %v, got %v", i, expectedRates[i], rate)
+		}
+	}
+}
+
+func TestLinearWarmupForwardsObservedLoss(t *testing.T) {
+	plateau, err := schedule.NewReduceOnPlateau(0.1, 0.5, 0, 0, 0)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	s, err := schedule.NewLinearWarmup(1, plateau)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	s.Observe(1)
+	s.Observe(1)
+	if rate := s.LearningRate(2, 2); math.Abs(rate-0.05) > acceptedError {
+		t.Errorf("expected rate to be 0.05, got %v", rate)
+	}
+}
+
+func TestReduceOnPlateauSetState(t *testing.T) {
+	original, err := schedule.NewReduceOnPlateau(0.1, 0.5, 2, 0.01, 0.03)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	restored, err := schedule.NewReduceOnPlateau(0.1, 0.5, 2, 0.01, 0.03)
+	if err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if _, ok := original.State()["best"]; ok {
+		t.Errorf("expected state to have no best before observing losses")
+	}
+	for _, loss := range []float64{1, 0.5, 0.495, 0.499} {
+		original.Observe(loss)
+	}
+
+	if err := restored.SetState(original.State()); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	for i, loss := range []float64{0.498, 0.3, 0.31, 0.32} {
+		original.Observe(loss)
+		restored.Observe(loss)
+		if original.LearningRate(i, i) != restored.LearningRate(i, i) {
+			t.Errorf("expected restored rate to be %v, got %v", original.LearningRate(i, i), restored.LearningRate(i, i))
+		}
+	}
+}
+
+func TestInvalidSchedules(t *testing.T) {
+	if _, err := schedule.NewConstant(0); err == nil {
+		t.Errorf("expected rate of 0 to be rejected")
+	}
+	if _, err := schedule.NewStepDecay(0.1, 1.5, 10); err == nil {
+		t.Errorf("expected factor bigger than 1 to be rejected")
+	}
+	if _, err := schedule.NewCosineAnnealing(0.1, 0.2, 10, 1); err == nil {
+		t.Errorf("expected min rate bigger than max rate to be rejected")
+	}
+	if _, err := schedule.NewLinearWarmup(10, nil); err == nil {
+		t.Errorf("expected nil schedule to be rejected")
+	}
+}